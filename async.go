@@ -0,0 +1,248 @@
+package log
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OverflowPolicy defines what to do if the queue of AsyncSink is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until there is room in the queue
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the entry which is being logged
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest entry in the queue
+	OverflowDropOldest
+	// OverflowDropBelowLevel drops entries less severe than DropLevel,
+	// and waits for others
+	OverflowDropBelowLevel
+)
+
+const defaultAsyncQueueSize = 1024
+
+// AsyncOptions defines options for AsyncSink
+type AsyncOptions struct {
+	QueueSize int
+	Policy    OverflowPolicy
+	DropLevel logrus.Level
+}
+
+type asyncEntry struct {
+	level logrus.Level
+	data  []byte
+}
+
+// AsyncSink writes entries of a logger in background. It takes over the
+// formatter and output of the logger: entries are formatted synchronously,
+// and written to the original output by a background goroutine.
+type AsyncSink struct {
+	options   AsyncOptions
+	formatter logrus.Formatter
+	out       io.Writer
+	queue     chan asyncEntry
+	dropped   uint64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+	closed  bool
+	done    chan struct{}
+}
+
+// NewAsyncSink makes logger asynchronous
+func NewAsyncSink(logger *logrus.Logger, options AsyncOptions) *AsyncSink {
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaultAsyncQueueSize
+	}
+
+	s := &AsyncSink{
+		options:   options,
+		formatter: logger.Formatter,
+		out:       logger.Out,
+		queue:     make(chan asyncEntry, options.QueueSize),
+		done:      make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	go s.run()
+
+	logger.Formatter = s
+	logger.Out = ioutil.Discard
+	return s
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+	for e := range s.queue {
+		s.write(e.level, e.data)
+		s.finish()
+	}
+}
+
+// write writes data to the original output, and passes level to outputs
+// which need it, such as SyncWriter
+func (s *AsyncSink) write(level logrus.Level, data []byte) error {
+	if w, ok := s.out.(levelWriter); ok {
+		return w.WriteLevel(level, data)
+	}
+	_, err := s.out.Write(data)
+	return err
+}
+
+func (s *AsyncSink) finish() {
+	s.mu.Lock()
+	s.pending--
+	if s.pending == 0 {
+		s.cond.Broadcast()
+	}
+	s.mu.Unlock()
+}
+
+// Format formats entry using the original formatter, and puts the result
+// into the queue. Fatal and panic entries are flushed before return.
+func (s *AsyncSink) Format(entry *logrus.Entry) ([]byte, error) {
+	serialized, err := s.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	closed := s.closed
+	if !closed {
+		s.pending++
+	}
+	s.mu.Unlock()
+
+	// Write to original output directly after closed
+	if closed {
+		return nil, s.write(entry.Level, serialized)
+	}
+
+	// Serialized data is owned by the buffer of entry, copy it
+	e := asyncEntry{
+		level: entry.Level,
+		data:  append([]byte(nil), serialized...),
+	}
+	s.enqueue(e)
+
+	if entry.Level <= logrus.FatalLevel {
+		s.Flush()
+	}
+	return nil, nil
+}
+
+func (s *AsyncSink) enqueue(e asyncEntry) {
+	select {
+	case s.queue <- e:
+		return
+	default:
+	}
+
+	switch s.options.Policy {
+	case OverflowDropNewest:
+		s.drop()
+		return
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.queue <- e:
+				return
+			case <-s.queue:
+				s.drop()
+			}
+		}
+	case OverflowDropBelowLevel:
+		if e.level > s.options.DropLevel {
+			s.drop()
+			return
+		}
+	}
+	s.queue <- e
+}
+
+func (s *AsyncSink) drop() {
+	atomic.AddUint64(&s.dropped, 1)
+	s.finish()
+}
+
+// Dropped returns number of dropped entries
+func (s *AsyncSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Flush waits until all queued entries are written
+func (s *AsyncSink) Flush() {
+	s.mu.Lock()
+	for s.pending > 0 {
+		s.cond.Wait()
+	}
+	s.mu.Unlock()
+
+	if w, ok := s.out.(flusher); ok {
+		w.Flush()
+	}
+}
+
+// Close flushes queued entries and stops the background goroutine.
+// Entries logged after Close are written synchronously.
+func (s *AsyncSink) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.Flush()
+	close(s.queue)
+	<-s.done
+}
+
+// flusher is implemented by writers which buffer data
+type flusher interface {
+	Flush()
+}
+
+// Flush waits until entries of all async sinks are written, and flushes
+// buffered data of writers
+func (v *MultiLogger) Flush() {
+	for _, l := range v.loggers() {
+		if s, ok := l.Formatter.(flusher); ok {
+			s.Flush()
+		}
+		if w, ok := l.Out.(flusher); ok {
+			w.Flush()
+		}
+	}
+}
+
+// Flush waits until entries of all async sinks are written, and flushes
+// buffered data of writers
+func Flush() {
+	mLogger.Flush()
+}
+
+// Dropped returns number of entries dropped by all async sinks
+func Dropped() uint64 {
+	var n uint64
+	for _, l := range mLogger.loggers() {
+		if s, ok := l.Formatter.(*AsyncSink); ok {
+			n += s.Dropped()
+		}
+	}
+	return n
+}
+
+func closeAsyncSinks(v *MultiLogger) {
+	for _, l := range v.loggers() {
+		if s, ok := l.Formatter.(*AsyncSink); ok {
+			s.Close()
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingWriter blocks writes until it is released
+type blockingWriter struct {
+	mu      sync.Mutex
+	buffer  bytes.Buffer
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		entered: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.entered <- struct{}{}
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buffer.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buffer.String()
+}
+
+func newAsyncTestLogger(w *blockingWriter, options AsyncOptions) (*logrus.Logger, *AsyncSink) {
+	logger := &logrus.Logger{
+		Out: w,
+		Formatter: &formatter.TextFormatter{
+			DisableTimestamp:       true,
+			DisableLevelTruncation: true,
+		},
+		Hooks: make(logrus.LevelHooks),
+		Level: logrus.TraceLevel,
+	}
+	return logger, NewAsyncSink(logger, options)
+}
+
+func TestAsyncSinkDropNewest(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		w      = newBlockingWriter()
+	)
+
+	logger, sink := newAsyncTestLogger(w, AsyncOptions{
+		QueueSize: 2,
+		Policy:    OverflowDropNewest,
+	})
+
+	// The first entry is taken by the writer, then queue is filled by two
+	logger.Info("info #1")
+	<-w.entered
+	logger.Info("info #2")
+	logger.Info("info #3")
+	logger.Info("info #4")
+	logger.Info("info #5")
+	close(w.release)
+	sink.Flush()
+
+	assert.Equal(uint64(2), sink.Dropped())
+	assert.Equal("INFO: info #1\nINFO: info #2\nINFO: info #3\n", w.String())
+	sink.Close()
+}
+
+func TestAsyncSinkDropBelowLevel(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		w      = newBlockingWriter()
+	)
+
+	logger, sink := newAsyncTestLogger(w, AsyncOptions{
+		QueueSize: 1,
+		Policy:    OverflowDropBelowLevel,
+		DropLevel: logrus.WarnLevel,
+	})
+
+	logger.Debug("debug")
+	<-w.entered
+	for i := 0; i < 9; i++ {
+		logger.Debug("debug")
+	}
+	assert.Equal(uint64(8), sink.Dropped())
+
+	// Queue is full, but errors are not dropped
+	go close(w.release)
+	logger.Error("error #1")
+	logger.Error("error #2")
+	sink.Flush()
+
+	assert.Equal(uint64(8), sink.Dropped())
+	assert.Contains(w.String(), "ERROR: error #1\n")
+	assert.Contains(w.String(), "ERROR: error #2\n")
+	sink.Close()
+
+	// Written synchronously after closed
+	logger.Warn("warn #3")
+	assert.Contains(w.String(), "WARNING: warn #3\n")
+}
+
+func TestAsyncFileLogger(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	o := Options{
+		LogFile: tmpLog,
+		stderr:  &buffer,
+		Async:   true,
+		AsyncOptions: AsyncOptions{
+			Policy: OverflowDropOldest,
+		},
+	}
+
+	// Fatal flushes async file logger before exit
+	demoLogger(o)
+	_, ok := mLogger.FileLogger.Formatter.(*AsyncSink)
+	assert.True(ok)
+
+	expect = `WARN[<time>]: warn #4
+WARN[<time>]: warning #5
+ERRO[<time>]: error #6
+FATA[<time>]: fatal #7
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+	Init(Options{})
+}
+
+func TestAsyncPanic(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile: tmpLog,
+		stderr:  &buffer,
+		Async:   true,
+	})
+	defer Init(Options{})
+
+	// Panic entry is written to all loggers before panic
+	assert.Panics(func() { WithField("code", 1).Panic("boom") })
+	assert.Panics(func() { Panicf("boom #%d", 2) })
+	assert.Equal(uint64(0), Dropped())
+
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal("PANI[<time>]: boom                                         (code=1)\nPANI[<time>]: boom #2\n",
+		filterTime(string(data)))
+}
@@ -0,0 +1,224 @@
+package log
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	auditHashSize = sha256.Size * 2
+	// Each line of audit log ends with " prev=<hash> hash=<hash>"
+	auditSuffixSize  = len(" prev=") + auditHashSize + len(" hash=") + auditHashSize
+	maxAuditLineSize = 1024 * 1024
+)
+
+var (
+	auditGenesis = strings.Repeat("0", auditHashSize)
+)
+
+// AuditError reports the first broken link of an audit log
+type AuditError struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
+}
+
+// AuditFormatter formats entries in one line, and appends hash of the
+// previous line and hash of this line, so any modified, inserted or
+// removed line breaks the hash chain.
+type AuditFormatter struct {
+	mu        sync.Mutex
+	formatter logrus.Formatter
+	key       []byte
+	prev      string
+}
+
+// NewAuditFormatter creates AuditFormatter whose chain starts from prev.
+// If key is not empty, HMAC-SHA256 with key is used instead of SHA256.
+func NewAuditFormatter(key []byte, prev string) *AuditFormatter {
+	if prev == "" {
+		prev = auditGenesis
+	}
+	return &AuditFormatter{
+		formatter: &formatter.TextFormatter{
+			DisableTimestamp:       false,
+			FullTimestamp:          true,
+			DisableLevelTruncation: false,
+			SafeMode:               formatter.SafeModeEscape,
+		},
+		key:  key,
+		prev: prev,
+	}
+}
+
+// Format renders entry with hashes of the chain
+func (f *AuditFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	serialized, err := f.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSuffix(string(serialized), "\n")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sum := auditHash(f.key, f.prev, text)
+	line := fmt.Sprintf("%s prev=%s hash=%s\n", text, f.prev, sum)
+	f.prev = sum
+	return []byte(line), nil
+}
+
+// auditHash returns hash of text linked to the previous hash
+func auditHash(key []byte, prev, text string) string {
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(prev))
+	h.Write([]byte("\n"))
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parseAuditLine splits line into text, previous hash and hash
+func parseAuditLine(line string) (text, prev, sum string, ok bool) {
+	if len(line) < auditSuffixSize {
+		return "", "", "", false
+	}
+	text = line[:len(line)-auditSuffixSize]
+	suffix := line[len(line)-auditSuffixSize:]
+	if !strings.HasPrefix(suffix, " prev=") ||
+		suffix[len(" prev=")+auditHashSize:len(" prev=")+auditHashSize+len(" hash=")] != " hash=" {
+		return "", "", "", false
+	}
+	prev = suffix[len(" prev=") : len(" prev=")+auditHashSize]
+	sum = suffix[len(suffix)-auditHashSize:]
+	return text, prev, sum, true
+}
+
+// walkAuditLog calls fn for each line of file. Missing file is ignored.
+func walkAuditLog(file string, fn func(lineno int, line string) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxAuditLineSize)
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		if err = fn(lineno, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// lastAuditHash returns hash of the last line of the audit log, or of its
+// rotated generation if the audit log is empty
+func lastAuditHash(file string) (string, error) {
+	var last string
+	for _, name := range []string{file, file + ".1"} {
+		err := walkAuditLog(name, func(lineno int, line string) error {
+			if _, _, sum, ok := parseAuditLine(line); ok {
+				last = sum
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		if last != "" {
+			break
+		}
+	}
+	return last, nil
+}
+
+// VerifyAuditLog checks hash chain of the audit log and its rotated
+// generation, and returns an *AuditError for the first broken link. If
+// the audit log has never been rotated, the first line must start the
+// chain. Otherwise the link of the first line of the rotated generation is
+// not checked, because older generations have been removed by rotation.
+func VerifyAuditLog(logFile string, key []byte) error {
+	logFile, err := resolveLogfile(logFile)
+	if err != nil {
+		return err
+	}
+
+	expect := auditGenesis
+	if _, err = os.Stat(logFile + ".1"); err == nil {
+		expect = ""
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	for _, name := range []string{logFile + ".1", logFile} {
+		err = walkAuditLog(name, func(lineno int, line string) error {
+			text, prev, sum, ok := parseAuditLine(line)
+			if !ok {
+				return &AuditError{name, lineno, "malformed line"}
+			}
+			if expect != "" && prev != expect {
+				return &AuditError{name, lineno, "previous hash mismatch"}
+			}
+			if auditHash(key, prev, text) != sum {
+				return &AuditError{name, lineno, "hash mismatch"}
+			}
+			expect = sum
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewAuditLogger creates a logger which writes hash chained entries into
+// logFile. The log file is opened and rotated like LogFile, and the chain
+// continues from the last entry of existing log.
+func NewAuditLogger(logFile string, key []byte, level logrus.Level) (*logrus.Logger, error) {
+	logFile, err := resolveLogfile(logFile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := openLogfile(logFile)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := lastAuditHash(logFile)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &logrus.Logger{
+		Out:          file,
+		Formatter:    NewAuditFormatter(key, prev),
+		Hooks:        make(logrus.LevelHooks),
+		Level:        level,
+		ExitFunc:     func(int) {},
+		ReportCaller: false,
+	}, nil
+}
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	auditLog := filepath.Join(tmpdir, "audit.log")
+	key := []byte("secret")
+
+	Init(Options{
+		AuditLog:      auditLog,
+		AuditKey:      key,
+		LogLevel:      "info",
+		LogRotateSize: 100,
+		stderr:        ioutil.Discard,
+	})
+	defer Init(Options{})
+
+	Info("login\nERRO[0000]: forged")
+	WithField("user", "jiangxin").Warn("grant")
+	assert.Nil(VerifyAuditLog(auditLog, key))
+
+	// Chain continues in the rotated log file
+	Init(Options{
+		AuditLog:      auditLog,
+		AuditKey:      key,
+		LogLevel:      "info",
+		LogRotateSize: 100,
+		stderr:        ioutil.Discard,
+	})
+	Error("revoke")
+	assert.Nil(VerifyAuditLog(auditLog, key))
+
+	data, err := ioutil.ReadFile(auditLog + ".1")
+	assert.Nil(err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	assert.Equal(2, len(lines))
+	assert.Contains(lines[0], `login\nERRO[0000]: forged`)
+
+	// Wrong key
+	err = VerifyAuditLog(auditLog, []byte("wrong"))
+	if assert.Error(err) {
+		assert.Equal(auditLog+".1:1: hash mismatch", err.Error())
+	}
+
+	// Remove the second line of rotated log
+	assert.Nil(ioutil.WriteFile(auditLog+".1", []byte(lines[0]+"\n"), 0644))
+	err = VerifyAuditLog(auditLog, key)
+	if assert.Error(err) {
+		assert.Equal(auditLog+":1: previous hash mismatch", err.Error())
+	}
+
+	// Modify message
+	assert.Nil(ioutil.WriteFile(auditLog+".1",
+		[]byte(strings.Replace(lines[0], "login", "logout", 1)+"\n"), 0644))
+	err = VerifyAuditLog(auditLog, key)
+	if assert.Error(err) {
+		assert.Equal(auditLog+".1:1: hash mismatch", err.Error())
+	}
+}
+
+func TestAuditLogWithError(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	auditLog := filepath.Join(tmpdir, "audit.log")
+
+	Init(Options{
+		AuditLog:   auditLog,
+		LogLevel:   "info",
+		ErrorStack: true,
+		stderr:     ioutil.Discard,
+	})
+	defer Init(Options{})
+
+	WithError(&wrapError{msg: "open", err: errors.New("denied")}).Error("failed")
+	Info("done")
+	assert.Nil(VerifyAuditLog(auditLog, nil))
+
+	data, err := ioutil.ReadFile(auditLog)
+	assert.Nil(err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if assert.Equal(2, len(lines)) {
+		assert.Contains(lines[0], `(error=open: denied)\n    caused by: denied\n    at `)
+	}
+}
+
+func TestAuditLogHeadTruncated(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	auditLog := filepath.Join(tmpdir, "audit.log")
+
+	Init(Options{
+		AuditLog: auditLog,
+		LogLevel: "info",
+		stderr:   ioutil.Discard,
+	})
+	defer Init(Options{})
+
+	Info("entry #1")
+	Info("entry #2")
+	Info("entry #3")
+	assert.Nil(VerifyAuditLog(auditLog, nil))
+
+	// Remove the first two lines of the log which is never rotated
+	data, err := ioutil.ReadFile(auditLog)
+	assert.Nil(err)
+	lines := strings.SplitAfter(string(data), "\n")
+	assert.Nil(ioutil.WriteFile(auditLog, []byte(lines[2]), 0644))
+	err = VerifyAuditLog(auditLog, nil)
+	if assert.Error(err) {
+		assert.Equal(auditLog+":1: previous hash mismatch", err.Error())
+	}
+}
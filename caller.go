@@ -0,0 +1,78 @@
+package log
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxCallerDepth = 32
+)
+
+var (
+	// packages which only forward entries to our loggers, and are skipped
+	// when looking for the caller
+	callerSkipPackages = []string{
+		"github.com/sirupsen/logrus",
+		reflect.TypeOf(MultiLogger{}).PkgPath(),
+		"log",
+		"log/slog",
+		"github.com/go-logr/logr",
+		"google.golang.org/grpc/grpclog",
+		"google.golang.org/grpc/internal/grpclog",
+		// frames of panic
+		"runtime",
+	}
+)
+
+// callerHook sets caller of entry to the frame of application. Caller
+// found by logrus is always in this package, because all entries are
+// passed to logrus through MultiLogger.
+type callerHook struct{}
+
+func (h *callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *callerHook) Fire(entry *logrus.Entry) error {
+	if frame := findCaller(); frame != nil {
+		entry.Caller = frame
+	}
+	return nil
+}
+
+// skipCallerFrame checks if frame belongs to packages which forward
+// entries. Test files of this package are callers like applications.
+func skipCallerFrame(frame *runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	pkg := formatter.PackagePath(frame.Function)
+	for _, skip := range callerSkipPackages {
+		if pkg == skip {
+			return true
+		}
+	}
+	return false
+}
+
+// findCaller returns the first frame outside of logging packages
+func findCaller() *runtime.Frame {
+	pcs := make([]uintptr, maxCallerDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !skipCallerFrame(&frame) {
+			return &frame
+		}
+		if !more {
+			break
+		}
+	}
+	return nil
+}
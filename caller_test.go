@@ -0,0 +1,82 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportCaller(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile:           tmpLog,
+		ReportCaller:      true,
+		CallerPath:        formatter.CallerPathBase,
+		ShortFunctionName: true,
+		stderr:            &buffer,
+	})
+	defer Init(Options{})
+
+	_, _, line, _ := runtime.Caller(0)
+	Warn("warn #1")
+	WithField("app", "demo").Errorf("error #%d", 2)
+
+	expect = fmt.Sprintf(`WARNING: caller_test.go:%d multi-log.TestReportCaller() warn #1
+ERROR: caller_test.go:%d multi-log.TestReportCaller() error #2                                     (app=demo)
+`, line+1, line+2)
+	assert.Equal(expect, buffer.String())
+
+	expect = `WARN[<time>]: warn #1
+ERRO[<time>]: error #2                                     (app=demo)
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+}
+
+func TestCallerFile(t *testing.T) {
+	assert := assert.New(t)
+
+	frame := &runtime.Frame{
+		File:     "/home/user/src/github.com/user/repo/pkg/main.go",
+		Function: "github.com/user/repo/pkg.(*T).Method",
+	}
+	assert.Equal("/home/user/src/github.com/user/repo/pkg/main.go",
+		formatter.CallerFile(frame, formatter.CallerPathFull))
+	assert.Equal("github.com/user/repo/pkg/main.go",
+		formatter.CallerFile(frame, formatter.CallerPathModule))
+	assert.Equal("main.go",
+		formatter.CallerFile(frame, formatter.CallerPathBase))
+	assert.Equal("github.com/user/repo/pkg.(*T).Method",
+		formatter.CallerFunction(frame, false))
+	assert.Equal("pkg.(*T).Method",
+		formatter.CallerFunction(frame, true))
+
+	// Main package of the test binary is not in the main module
+	frame = &runtime.Frame{
+		File:     "/home/user/src/github.com/user/repo/cmd/probe/main.go",
+		Function: "main.main",
+	}
+	assert.Equal("/home/user/src/github.com/user/repo/cmd/probe/main.go",
+		formatter.CallerFile(frame, formatter.CallerPathModule))
+}
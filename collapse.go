@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const defaultCollapseTimeout = 2 * time.Second
+
+// collapseWriter collapses consecutive identical lines. The first line is
+// written immediately, and a short line with the repeat counter, such as
+// "last message repeated 2 times", is written when a different line
+// arrives or after timeout.
+type collapseWriter struct {
+	mu       sync.Mutex
+	out      io.Writer
+	timeout  time.Duration
+	last     []byte
+	repeated int
+	timer    *time.Timer
+}
+
+func newCollapseWriter(out io.Writer, timeout time.Duration) *collapseWriter {
+	if timeout <= 0 {
+		timeout = defaultCollapseTimeout
+	}
+	return &collapseWriter{
+		out:     out,
+		timeout: timeout,
+	}
+}
+
+func (w *collapseWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.last != nil && bytes.Equal(p, w.last) {
+		w.repeated++
+		if w.timer == nil {
+			w.timer = time.AfterFunc(w.timeout, w.Flush)
+		}
+		return len(p), nil
+	}
+
+	if err := w.flush(); err != nil {
+		return 0, err
+	}
+	w.last = append(w.last[:0], p...)
+	return w.out.Write(p)
+}
+
+// Flush writes the repeat counter of the last line
+func (w *collapseWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+}
+
+func (w *collapseWriter) flush() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.repeated == 0 {
+		return nil
+	}
+
+	unit := "times"
+	if w.repeated == 1 {
+		unit = "time"
+	}
+	_, err := fmt.Fprintf(w.out, "last message repeated %d %s\n", w.repeated, unit)
+	w.repeated = 0
+	return err
+}
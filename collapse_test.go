@@ -0,0 +1,81 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseDuplicates(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile:            tmpLog,
+		CollapseDuplicates: true,
+		CollapseTimeout:    time.Hour,
+		stderr:             &buffer,
+	})
+
+	for i := 0; i < 3; i++ {
+		Warn("retry")
+	}
+	Error("failed")
+	Error("failed")
+	Flush()
+	Init(Options{})
+
+	expect = `WARNING: retry
+last message repeated 2 times
+ERROR: failed
+last message repeated 1 time
+`
+	assert.Equal(expect, buffer.String())
+
+	expect = `WARN[<time>]: retry
+WARN[<time>]: retry
+WARN[<time>]: retry
+ERRO[<time>]: failed
+ERRO[<time>]: failed
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+}
+
+func TestCollapseTimeout(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	w := newCollapseWriter(&buffer, 10*time.Millisecond)
+	w.Write([]byte("WARNING: retry\n"))
+	w.Write([]byte("WARNING: retry\n"))
+	time.Sleep(100 * time.Millisecond)
+	w.Write([]byte("WARNING: retry\n"))
+	w.Flush()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	assert.Equal("WARNING: retry\n"+
+		"last message repeated 1 time\n"+
+		"last message repeated 1 time\n",
+		buffer.String())
+}
@@ -0,0 +1,321 @@
+package log
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ContextExtractor extracts fields, such as request ID, from context
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+var (
+	extractorsMu      sync.RWMutex
+	contextExtractors []ContextExtractor
+)
+
+// RegisterContextExtractor registers an extractor, and fields extracted
+// from context are added to entries logged by WithContext and Ctx methods
+func RegisterContextExtractor(extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	contextExtractors = append(contextExtractors, extractor)
+}
+
+func contextFields(ctx context.Context) logrus.Fields {
+	fields := logrus.Fields{}
+	if ctx == nil {
+		return fields
+	}
+
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	for _, extractor := range contextExtractors {
+		for k, v := range extractor(ctx) {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// WithContext returns a logger with fields extracted from ctx
+func WithContext(ctx context.Context) *MultiLoggerWithFields {
+	return mLogger.WithContext(ctx)
+}
+
+// WithContext returns a logger with fields extracted from ctx. Fields of
+// MultiLoggerWithFields are kept, and overridden by fields from ctx.
+func (v *MultiLogger) WithContext(ctx context.Context) *MultiLoggerWithFields {
+	return v.WithFields(contextFields(ctx))
+}
+
+// LogfCtx is Logf with fields extracted from ctx
+func (v *MultiLogger) LogfCtx(ctx context.Context, level logrus.Level, format string, args ...interface{}) {
+	v.WithContext(ctx).Logf(level, format, args...)
+}
+
+// TracefCtx is Tracef with fields extracted from ctx
+func (v *MultiLogger) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Tracef(format, args...)
+}
+
+// DebugfCtx is Debugf with fields extracted from ctx
+func (v *MultiLogger) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Debugf(format, args...)
+}
+
+// InfofCtx is Infof with fields extracted from ctx
+func (v *MultiLogger) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Infof(format, args...)
+}
+
+// WarnfCtx is Warnf with fields extracted from ctx
+func (v *MultiLogger) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Warnf(format, args...)
+}
+
+// WarningfCtx is Warningf with fields extracted from ctx
+func (v *MultiLogger) WarningfCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Warningf(format, args...)
+}
+
+// ErrorfCtx is Errorf with fields extracted from ctx
+func (v *MultiLogger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Errorf(format, args...)
+}
+
+// FatalfCtx is Fatalf with fields extracted from ctx
+func (v *MultiLogger) FatalfCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Fatalf(format, args...)
+}
+
+// PanicfCtx is Panicf with fields extracted from ctx
+func (v *MultiLogger) PanicfCtx(ctx context.Context, format string, args ...interface{}) {
+	v.WithContext(ctx).Panicf(format, args...)
+}
+
+// LogCtx is Log with fields extracted from ctx
+func (v *MultiLogger) LogCtx(ctx context.Context, level logrus.Level, args ...interface{}) {
+	v.WithContext(ctx).Log(level, args...)
+}
+
+// TraceCtx is Trace with fields extracted from ctx
+func (v *MultiLogger) TraceCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Trace(args...)
+}
+
+// DebugCtx is Debug with fields extracted from ctx
+func (v *MultiLogger) DebugCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Debug(args...)
+}
+
+// InfoCtx is Info with fields extracted from ctx
+func (v *MultiLogger) InfoCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Info(args...)
+}
+
+// WarnCtx is Warn with fields extracted from ctx
+func (v *MultiLogger) WarnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Warn(args...)
+}
+
+// WarningCtx is Warning with fields extracted from ctx
+func (v *MultiLogger) WarningCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Warning(args...)
+}
+
+// ErrorCtx is Error with fields extracted from ctx
+func (v *MultiLogger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Error(args...)
+}
+
+// FatalCtx is Fatal with fields extracted from ctx
+func (v *MultiLogger) FatalCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Fatal(args...)
+}
+
+// PanicCtx is Panic with fields extracted from ctx
+func (v *MultiLogger) PanicCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Panic(args...)
+}
+
+// LoglnCtx is Logln with fields extracted from ctx
+func (v *MultiLogger) LoglnCtx(ctx context.Context, level logrus.Level, args ...interface{}) {
+	v.WithContext(ctx).Logln(level, args...)
+}
+
+// TracelnCtx is Traceln with fields extracted from ctx
+func (v *MultiLogger) TracelnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Traceln(args...)
+}
+
+// DebuglnCtx is Debugln with fields extracted from ctx
+func (v *MultiLogger) DebuglnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Debugln(args...)
+}
+
+// InfolnCtx is Infoln with fields extracted from ctx
+func (v *MultiLogger) InfolnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Infoln(args...)
+}
+
+// WarnlnCtx is Warnln with fields extracted from ctx
+func (v *MultiLogger) WarnlnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Warnln(args...)
+}
+
+// WarninglnCtx is Warningln with fields extracted from ctx
+func (v *MultiLogger) WarninglnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Warningln(args...)
+}
+
+// ErrorlnCtx is Errorln with fields extracted from ctx
+func (v *MultiLogger) ErrorlnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Errorln(args...)
+}
+
+// FatallnCtx is Fatalln with fields extracted from ctx
+func (v *MultiLogger) FatallnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Fatalln(args...)
+}
+
+// PaniclnCtx is Panicln with fields extracted from ctx
+func (v *MultiLogger) PaniclnCtx(ctx context.Context, args ...interface{}) {
+	v.WithContext(ctx).Panicln(args...)
+}
+
+// LogfCtx is Logf with fields extracted from ctx
+func LogfCtx(ctx context.Context, level logrus.Level, format string, args ...interface{}) {
+	WithContext(ctx).Logf(level, format, args...)
+}
+
+// TracefCtx is Tracef with fields extracted from ctx
+func TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Tracef(format, args...)
+}
+
+// DebugfCtx is Debugf with fields extracted from ctx
+func DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Debugf(format, args...)
+}
+
+// InfofCtx is Infof with fields extracted from ctx
+func InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Infof(format, args...)
+}
+
+// WarnfCtx is Warnf with fields extracted from ctx
+func WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Warnf(format, args...)
+}
+
+// WarningfCtx is Warningf with fields extracted from ctx
+func WarningfCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Warningf(format, args...)
+}
+
+// ErrorfCtx is Errorf with fields extracted from ctx
+func ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Errorf(format, args...)
+}
+
+// FatalfCtx is Fatalf with fields extracted from ctx
+func FatalfCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Fatalf(format, args...)
+}
+
+// PanicfCtx is Panicf with fields extracted from ctx
+func PanicfCtx(ctx context.Context, format string, args ...interface{}) {
+	WithContext(ctx).Panicf(format, args...)
+}
+
+// LogCtx is Log with fields extracted from ctx
+func LogCtx(ctx context.Context, level logrus.Level, args ...interface{}) {
+	WithContext(ctx).Log(level, args...)
+}
+
+// TraceCtx is Trace with fields extracted from ctx
+func TraceCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Trace(args...)
+}
+
+// DebugCtx is Debug with fields extracted from ctx
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Debug(args...)
+}
+
+// InfoCtx is Info with fields extracted from ctx
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Info(args...)
+}
+
+// WarnCtx is Warn with fields extracted from ctx
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Warn(args...)
+}
+
+// WarningCtx is Warning with fields extracted from ctx
+func WarningCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Warning(args...)
+}
+
+// ErrorCtx is Error with fields extracted from ctx
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Error(args...)
+}
+
+// FatalCtx is Fatal with fields extracted from ctx
+func FatalCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Fatal(args...)
+}
+
+// PanicCtx is Panic with fields extracted from ctx
+func PanicCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Panic(args...)
+}
+
+// LoglnCtx is Logln with fields extracted from ctx
+func LoglnCtx(ctx context.Context, level logrus.Level, args ...interface{}) {
+	WithContext(ctx).Logln(level, args...)
+}
+
+// TracelnCtx is Traceln with fields extracted from ctx
+func TracelnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Traceln(args...)
+}
+
+// DebuglnCtx is Debugln with fields extracted from ctx
+func DebuglnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Debugln(args...)
+}
+
+// InfolnCtx is Infoln with fields extracted from ctx
+func InfolnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Infoln(args...)
+}
+
+// WarnlnCtx is Warnln with fields extracted from ctx
+func WarnlnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Warnln(args...)
+}
+
+// WarninglnCtx is Warningln with fields extracted from ctx
+func WarninglnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Warningln(args...)
+}
+
+// ErrorlnCtx is Errorln with fields extracted from ctx
+func ErrorlnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Errorln(args...)
+}
+
+// FatallnCtx is Fatalln with fields extracted from ctx
+func FatallnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Fatalln(args...)
+}
+
+// PaniclnCtx is Panicln with fields extracted from ctx
+func PaniclnCtx(ctx context.Context, args ...interface{}) {
+	WithContext(ctx).Panicln(args...)
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey string
+
+func TestContextLogger(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	saved := contextExtractors
+	defer func() {
+		contextExtractors = saved
+	}()
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		if id, ok := ctx.Value(contextKey("request-id")).(string); ok {
+			return map[string]interface{}{"request-id": id}
+		}
+		return nil
+	})
+
+	Init(Options{
+		LogFile: tmpLog,
+		stderr:  &buffer,
+	})
+
+	ctx := context.WithValue(context.Background(), contextKey("request-id"), "1234")
+	WarnCtx(ctx, "warn #", 1)
+	ErrorfCtx(ctx, "error #%d", 2)
+	WithField("tenant", "demo").ErrorlnCtx(ctx, "error #", 3)
+	WithContext(context.Background()).Error("error #4")
+
+	expect = `WARNING: warn #1                                      (request-id=1234)
+ERROR: error #2                                     (request-id=1234)
+ERROR: error # 3                                    (request-id=1234 tenant=demo)
+ERROR: error #4
+`
+	assert.Equal(expect, buffer.String())
+
+	expect = `WARN[<time>]: warn #1                                      (request-id=1234)
+ERRO[<time>]: error #2                                     (request-id=1234)
+ERRO[<time>]: error # 3                                    (request-id=1234 tenant=demo)
+ERRO[<time>]: error #4
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+}
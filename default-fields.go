@@ -0,0 +1,73 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AutoField selects fields which are detected automatically
+type AutoField int
+
+const (
+	// HostnameField adds "hostname" field
+	HostnameField AutoField = 1 << iota
+	// PidField adds "pid" field
+	PidField
+	// ExecutableField adds "exe" field, the name of the executable
+	ExecutableField
+	// VersionField adds "version" field, the module version of main package
+	VersionField
+)
+
+// defaultFieldsHook adds default fields to entries, unless the entry has
+// fields with the same keys
+type defaultFieldsHook struct {
+	fields logrus.Fields
+}
+
+func (h *defaultFieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *defaultFieldsHook) Fire(entry *logrus.Entry) error {
+	for k, v := range h.fields {
+		if _, ok := entry.Data[k]; !ok {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
+
+// defaultFields returns static default fields and automatic fields
+func defaultFields(static map[string]interface{}, auto AutoField) logrus.Fields {
+	fields := logrus.Fields{}
+
+	if auto&HostnameField != 0 {
+		if hostname, err := os.Hostname(); err == nil {
+			fields["hostname"] = hostname
+		}
+	}
+	if auto&PidField != 0 {
+		fields["pid"] = os.Getpid()
+	}
+	if auto&ExecutableField != 0 {
+		if exe, err := os.Executable(); err == nil {
+			fields["exe"] = filepath.Base(exe)
+		} else {
+			fields["exe"] = filepath.Base(os.Args[0])
+		}
+	}
+	if auto&VersionField != 0 {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			fields["version"] = info.Main.Version
+		}
+	}
+
+	for k, v := range static {
+		fields[k] = v
+	}
+	return fields
+}
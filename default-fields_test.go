@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultFields(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile: tmpLog,
+		DefaultFields: map[string]interface{}{
+			"app": "demo",
+		},
+		AutoFields:            PidField,
+		DefaultFieldsFileOnly: true,
+		stderr:                &buffer,
+	})
+	defer Init(Options{})
+
+	Warn("warn #1")
+	WithField("app", "override").Error("error #2")
+
+	expect = `WARNING: warn #1
+ERROR: error #2                                     (app=override)
+`
+	assert.Equal(expect, buffer.String())
+
+	expect = fmt.Sprintf(`WARN[<time>]: warn #1                                      (app=demo pid=%d)
+ERRO[<time>]: error #2                                     (app=override pid=%d)
+`, os.Getpid(), os.Getpid())
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+
+	fields := defaultFields(nil, HostnameField|ExecutableField|VersionField)
+	assert.NotEmpty(fields["hostname"])
+	assert.NotEmpty(fields["exe"])
+	assert.Contains(fields, "version")
+}
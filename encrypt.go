@@ -0,0 +1,270 @@
+package log
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// Plain text larger than maxEncryptChunkSize is split into chunks
+	maxEncryptChunkSize = 64 * 1024
+	encryptLengthSize   = 4
+	encryptSaltSize     = 16
+	encryptIndexSize    = 8
+	// Overhead of AES-GCM, which is the size of authentication tag
+	encryptOverhead = 16
+	// Each chunk starts with length, salt, index and final flag
+	encryptHeaderSize = encryptLengthSize + encryptSaltSize + encryptIndexSize + 1
+)
+
+var (
+	// ErrDecrypt is returned if a chunk of encrypted log is corrupted or
+	// the key is wrong
+	ErrDecrypt = errors.New("fail to decrypt log: message authentication failed")
+	// ErrDecryptSequence is returned if chunks of encrypted log are
+	// missing, duplicated or out of order
+	ErrDecryptSequence = errors.New("fail to decrypt log: chunks are missing or out of order")
+)
+
+// EncryptWriter encrypts each write in authenticated chunks using AES-GCM.
+//
+// Each EncryptWriter starts a new stream with a random salt, and chunks of
+// the stream are sealed by a key derived from the key and the salt, using
+// the index of chunk as nonce. Each chunk is the length of the rest of the
+// chunk in 4 bytes (big endian), the salt, the index in 8 bytes (big
+// endian), a flag which is 1 for the last chunk of a write, and the sealed
+// data. The index and the flag are also authenticated, so reordered,
+// dropped, duplicated or copied chunks are detected. Streams are
+// independent, so a truncated file, or a file appended by several
+// processes, can be decrypted up to the last complete chunk.
+type EncryptWriter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	aead  cipher.AEAD
+	salt  []byte
+	index uint64
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("bad encrypt key: %s", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// newStreamAEAD creates AEAD of the stream using a key derived from key and
+// salt, which has the same size as key
+func newStreamAEAD(key, salt []byte) (cipher.AEAD, error) {
+	if _, err := newAEAD(key); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(salt)
+	return newAEAD(mac.Sum(nil)[:len(key)])
+}
+
+// encryptNonce returns nonce of the chunk at index
+func encryptNonce(aead cipher.AEAD, index uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-encryptIndexSize:], index)
+	return nonce
+}
+
+// NewEncryptWriter creates EncryptWriter which writes to out. Key must be
+// 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewEncryptWriter(out io.Writer, key []byte) (*EncryptWriter, error) {
+	salt := make([]byte, encryptSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newStreamAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptWriter{
+		out:  out,
+		aead: aead,
+		salt: salt,
+	}, nil
+}
+
+// Write encrypts p and writes chunks in one write to the output
+func (w *EncryptWriter) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for data := p; len(data) > 0; {
+		n := len(data)
+		if n > maxEncryptChunkSize {
+			n = maxEncryptChunkSize
+		}
+		w.seal(&buf, data[:n], n == len(data))
+		data = data[n:]
+	}
+
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// seal appends the next encrypted chunk of data into buf
+func (w *EncryptWriter) seal(buf *bytes.Buffer, data []byte, final bool) {
+	header := make([]byte, encryptHeaderSize)
+	copy(header[encryptLengthSize:], w.salt)
+	binary.BigEndian.PutUint64(header[encryptLengthSize+encryptSaltSize:], w.index)
+	if final {
+		header[encryptHeaderSize-1] = 1
+	}
+
+	// Salt, index and flag are authenticated as additional data
+	sealed := w.aead.Seal(nil, encryptNonce(w.aead, w.index), data, header[encryptLengthSize:])
+	w.index++
+
+	binary.BigEndian.PutUint32(header, uint32(encryptHeaderSize-encryptLengthSize+len(sealed)))
+	buf.Write(header)
+	buf.Write(sealed)
+}
+
+// Sync syncs the output if it can be synced
+func (w *EncryptWriter) Sync() error {
+	if s, ok := w.out.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close closes the output if it is an io.Closer
+func (w *EncryptWriter) Close() error {
+	if c, ok := w.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// decryptStream is the state of a stream read by decryptReader
+type decryptStream struct {
+	aead  cipher.AEAD
+	next  uint64
+	final bool
+}
+
+// decryptReader reads chunks written by EncryptWriter
+type decryptReader struct {
+	in      io.Reader
+	key     []byte
+	streams map[string]*decryptStream
+	buf     []byte
+	err     error
+}
+
+// NewDecryptReader returns a reader which decrypts log encrypted by
+// EncryptWriter. If the last chunk is incomplete, or the last write of a
+// stream is incomplete, all complete chunks are read and
+// io.ErrUnexpectedEOF is returned. If a chunk is corrupted, ErrDecrypt is
+// returned, and if chunks are missing or out of order, ErrDecryptSequence
+// is returned.
+func NewDecryptReader(in io.Reader, key []byte) (io.Reader, error) {
+	if _, err := newAEAD(key); err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		in:      in,
+		key:     key,
+		streams: make(map[string]*decryptStream),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.buf, r.err = r.next()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next chunk
+func (r *decryptReader) next() ([]byte, error) {
+	header := make([]byte, encryptHeaderSize)
+	if _, err := io.ReadFull(r.in, header[:encryptLengthSize]); err != nil {
+		if err == io.EOF {
+			return nil, r.checkFinal()
+		}
+		return nil, err
+	}
+
+	size := int(binary.BigEndian.Uint32(header)) - (encryptHeaderSize - encryptLengthSize)
+	if size < 0 || size > maxEncryptChunkSize+encryptOverhead {
+		return nil, ErrDecrypt
+	}
+
+	chunk := make([]byte, size)
+	_, err := io.ReadFull(r.in, header[encryptLengthSize:])
+	if err == nil {
+		_, err = io.ReadFull(r.in, chunk)
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	salt := header[encryptLengthSize : encryptLengthSize+encryptSaltSize]
+	index := binary.BigEndian.Uint64(header[encryptLengthSize+encryptSaltSize:])
+	stream, err := r.stream(salt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := stream.aead.Open(nil, encryptNonce(stream.aead, index), chunk, header[encryptLengthSize:])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	if index != stream.next {
+		return nil, ErrDecryptSequence
+	}
+	stream.next++
+	stream.final = header[encryptHeaderSize-1] == 1
+	return data, nil
+}
+
+// stream returns state of the stream of salt
+func (r *decryptReader) stream(salt []byte) (*decryptStream, error) {
+	if stream, ok := r.streams[string(salt)]; ok {
+		return stream, nil
+	}
+	aead, err := newStreamAEAD(r.key, salt)
+	if err != nil {
+		return nil, err
+	}
+	stream := &decryptStream{aead: aead, final: true}
+	r.streams[string(salt)] = stream
+	return stream, nil
+}
+
+// checkFinal returns io.ErrUnexpectedEOF if the last write of any stream
+// is incomplete, or io.EOF
+func (r *decryptReader) checkFinal() error {
+	for _, stream := range r.streams {
+		if !stream.final {
+			return io.ErrUnexpectedEOF
+		}
+	}
+	return io.EOF
+}
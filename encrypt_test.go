@@ -0,0 +1,181 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptLogfile(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+	key := []byte("0123456789abcdef")
+
+	Init(Options{
+		LogFile:    tmpLog,
+		EncryptKey: key,
+		stderr:     ioutil.Discard,
+	})
+	defer Init(Options{})
+
+	Warn("warn #1")
+	Error("error #2")
+
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.NotContains(string(data), "warn #1")
+
+	r, err := NewDecryptReader(bytes.NewReader(data), key)
+	assert.Nil(err)
+	plain, err := ioutil.ReadAll(r)
+	assert.Nil(err)
+	expect = `WARN[<time>]: warn #1
+ERRO[<time>]: error #2
+`
+	assert.Equal(expect, filterTime(string(plain)))
+
+	// Truncated file is decrypted up to the last complete chunk
+	r, err = NewDecryptReader(bytes.NewReader(data[:len(data)-1]), key)
+	assert.Nil(err)
+	plain, err = ioutil.ReadAll(r)
+	assert.Equal(io.ErrUnexpectedEOF, err)
+	assert.Equal("WARN[<time>]: warn #1\n", filterTime(string(plain)))
+
+	// Wrong key
+	r, err = NewDecryptReader(bytes.NewReader(data), []byte("fedcba9876543210"))
+	assert.Nil(err)
+	_, err = ioutil.ReadAll(r)
+	assert.Equal(ErrDecrypt, err)
+}
+
+func TestEncryptLargeWrite(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		key    = []byte("0123456789abcdef0123456789abcdef")
+	)
+
+	w, err := NewEncryptWriter(&buffer, key)
+	assert.Nil(err)
+	text := strings.Repeat("x", maxEncryptChunkSize*2+10)
+	n, err := w.Write([]byte(text))
+	assert.Nil(err)
+	assert.Equal(len(text), n)
+
+	r, err := NewDecryptReader(&buffer, key)
+	assert.Nil(err)
+	plain, err := ioutil.ReadAll(r)
+	assert.Nil(err)
+	assert.Equal(text, string(plain))
+
+	_, err = NewEncryptWriter(&buffer, []byte("short"))
+	assert.Error(err)
+}
+
+// encryptChunks splits encrypted data into chunks
+func encryptChunks(data []byte) [][]byte {
+	var chunks [][]byte
+	for len(data) > 0 {
+		n := encryptLengthSize + int(binary.BigEndian.Uint32(data))
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return chunks
+}
+
+func decryptChunks(key []byte, chunks ...[]byte) (string, error) {
+	r, err := NewDecryptReader(bytes.NewReader(bytes.Join(chunks, nil)), key)
+	if err != nil {
+		return "", err
+	}
+	plain, err := ioutil.ReadAll(r)
+	return string(plain), err
+}
+
+func TestEncryptChunkSequence(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		file1  bytes.Buffer
+		file2  bytes.Buffer
+		key    = []byte("0123456789abcdef")
+	)
+
+	w1, err := NewEncryptWriter(&file1, key)
+	assert.Nil(err)
+	w2, err := NewEncryptWriter(&file2, key)
+	assert.Nil(err)
+	for _, s := range []string{"a\n", "b\n", "c\n"} {
+		w1.Write([]byte(s))
+		w2.Write([]byte(strings.ToUpper(s)))
+	}
+	c1 := encryptChunks(file1.Bytes())
+	c2 := encryptChunks(file2.Bytes())
+	assert.Equal(3, len(c1))
+
+	// Streams appended by several writers are interleaved
+	plain, err := decryptChunks(key, c1[0], c2[0], c1[1], c2[1], c2[2], c1[2])
+	assert.Nil(err)
+	assert.Equal("a\nA\nb\nB\nC\nc\n", plain)
+
+	// Reordered chunks
+	plain, err = decryptChunks(key, c1[0], c1[2], c1[1])
+	assert.Equal(ErrDecryptSequence, err)
+	assert.Equal("a\n", plain)
+
+	// Dropped chunks
+	_, err = decryptChunks(key, c1[1], c1[2])
+	assert.Equal(ErrDecryptSequence, err)
+	_, err = decryptChunks(key, c1[0], c1[2])
+	assert.Equal(ErrDecryptSequence, err)
+
+	// Duplicated chunk
+	_, err = decryptChunks(key, c1[0], c1[1], c1[1])
+	assert.Equal(ErrDecryptSequence, err)
+
+	// Chunk copied from another file
+	_, err = decryptChunks(key, c1[0], c1[1], c2[2])
+	assert.Equal(ErrDecryptSequence, err)
+
+	// Tampered index
+	chunk := append([]byte(nil), c1[1]...)
+	chunk[encryptLengthSize+encryptSaltSize+encryptIndexSize-1] = 2
+	_, err = decryptChunks(key, c1[0], chunk)
+	assert.Equal(ErrDecrypt, err)
+}
+
+func TestEncryptIncompleteWrite(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		key    = []byte("0123456789abcdef")
+	)
+
+	w, err := NewEncryptWriter(&buffer, key)
+	assert.Nil(err)
+	w.Write([]byte("a\n"))
+	w.Write([]byte(strings.Repeat("x", maxEncryptChunkSize+10)))
+	chunks := encryptChunks(buffer.Bytes())
+	assert.Equal(3, len(chunks))
+
+	// The last chunk of a write is dropped
+	plain, err := decryptChunks(key, chunks[0], chunks[1])
+	assert.Equal(io.ErrUnexpectedEOF, err)
+	assert.Equal("a\n"+strings.Repeat("x", maxEncryptChunkSize), plain)
+}
@@ -0,0 +1,29 @@
+package log
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// entryTimeKey is the field key to pass time of entry, which is used by
+// adapters of records with their own time, such as SlogHandler. Logrus
+// sets time of entries with zero time to now, so time is passed by field
+// and set by entryTimeHook after that. Zero time is kept, and formatters
+// do not show it.
+const entryTimeKey = "\x00time"
+
+// entryTimeHook sets time of entry from field entryTimeKey
+type entryTimeHook struct{}
+
+func (h *entryTimeHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *entryTimeHook) Fire(entry *logrus.Entry) error {
+	if t, ok := entry.Data[entryTimeKey].(time.Time); ok {
+		entry.Time = t
+		delete(entry.Data, entryTimeKey)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"runtime"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/sirupsen/logrus"
+)
+
+const maxStackDepth = 32
+
+var (
+	// errorKey is the field key used by WithError
+	errorKey = logrus.ErrorKey
+	// captureErrorStack captures stack trace in WithError
+	captureErrorStack bool
+)
+
+// stackError attaches the stack trace captured by WithError to an error
+type stackError struct {
+	error
+	stack []uintptr
+}
+
+func (e *stackError) Unwrap() error {
+	return e.error
+}
+
+// StackTrace returns program counters of the captured stack
+func (e *stackError) StackTrace() []uintptr {
+	return e.stack
+}
+
+// withStack captures stack trace of the caller of WithError, unless err
+// already has one
+func withStack(err error) error {
+	if err == nil || !captureErrorStack || len(formatter.ErrorStack(err)) > 0 {
+		return err
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	// Skip runtime.Callers, withStack and WithError
+	n := runtime.Callers(3, pcs)
+	return &stackError{
+		error: err,
+		stack: pcs[:n],
+	}
+}
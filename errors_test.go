@@ -0,0 +1,124 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (e *wrapError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+type joinError []error
+
+func (e joinError) Error() string {
+	msgs := []string{}
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e joinError) Unwrap() []error {
+	return e
+}
+
+func TestWithErrorChain(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		ErrorKey: "err",
+		stderr:   &buffer,
+	})
+	defer Init(Options{})
+
+	err := &wrapError{
+		msg: "load config",
+		err: joinError{
+			errors.New("permission denied"),
+			&wrapError{msg: "parse", err: errors.New("bad syntax")},
+		},
+	}
+	WithError(err).Error("failed")
+	WithError(errors.New("plain")).Error("failed")
+
+	expect = `ERROR: failed                                       (err=load config: permission denied; parse: bad syntax)
+    caused by: permission denied; parse: bad syntax
+    caused by: permission denied
+    caused by: parse: bad syntax
+    caused by: bad syntax
+ERROR: failed                                       (err=plain)
+`
+	assert.Equal(expect, buffer.String())
+}
+
+func TestWithErrorStack(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	Init(Options{
+		ErrorStack: true,
+		stderr:     &buffer,
+	})
+	defer Init(Options{})
+
+	WithField("size", "10MB").WithError(errors.New("timeout")).Error("failed")
+
+	lines := strings.Split(buffer.String(), "\n")
+	assert.Equal("ERROR: failed                                       (error=timeout size=10MB)", lines[0])
+	assert.Regexp(regexp.MustCompile(`^    at .*\.TestWithErrorStack \(.*errors_test.go:\d+\)$`), lines[1])
+}
+
+func TestStructuredErrorFormatter(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		data   struct {
+			Error formatter.ErrorDetails `json:"error"`
+			Msg   string                 `json:"msg"`
+		}
+	)
+
+	Init(Options{
+		ErrorStack: true,
+		stderr:     &buffer,
+	})
+	defer Init(Options{})
+	mLogger.StdLogger.Formatter = &formatter.StructuredErrorFormatter{
+		Formatter: &logrus.JSONFormatter{},
+	}
+
+	err := &wrapError{msg: "open", err: errors.New("denied")}
+	WithError(err).Error("failed")
+
+	assert.Nil(json.Unmarshal(buffer.Bytes(), &data))
+	assert.Equal("failed", data.Msg)
+	assert.Equal("open: denied", data.Error.Error)
+	assert.Equal([]string{"denied"}, data.Error.Causes)
+	if assert.NotEmpty(data.Error.Stack) {
+		assert.Regexp(regexp.MustCompile(`\.TestStructuredErrorFormatter \(.*errors_test.go:\d+\)$`), data.Error.Stack[0])
+	}
+}
@@ -0,0 +1,210 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// FallbackPolicy defines where to write entries if log file is unwritable
+type FallbackPolicy int
+
+const (
+	// FallbackNone keeps writing to the log file, and logrus reports
+	// every failure
+	FallbackNone FallbackPolicy = iota
+	// FallbackDiscard discards entries
+	FallbackDiscard
+	// FallbackMemory keeps recent entries in memory, and writes them into
+	// the log file after it is recovered
+	FallbackMemory
+	// FallbackFile writes entries into another log file
+	FallbackFile
+)
+
+const (
+	defaultFallbackMemorySize    = 1024 * 1024
+	defaultFallbackRetryInterval = 30 * time.Second
+)
+
+// FallbackOptions defines options for FallbackWriter
+type FallbackOptions struct {
+	Policy FallbackPolicy
+	// File is the log file used by FallbackFile
+	File string
+	// MemorySize is the max bytes of entries kept by FallbackMemory
+	MemorySize int
+	// RetryInterval is the interval to retry the original log file
+	RetryInterval time.Duration
+}
+
+// FallbackWriter writes to the log file, and switches to the fallback if
+// a write fails. The log file is reopened on the first write after each
+// RetryInterval, and is used again if the write succeeds.
+type FallbackWriter struct {
+	mu        sync.Mutex
+	name      string
+	out       io.Writer
+	reopen    func() (io.Writer, error)
+	options   FallbackOptions
+	fallback  io.Writer
+	memory    [][]byte
+	memSize   int
+	lastRetry time.Time
+	diverted  uint64
+}
+
+// NewFallbackWriter creates FallbackWriter for out, which is the log file
+// name. Function reopen opens the log file again.
+func NewFallbackWriter(name string, out io.Writer, reopen func() (io.Writer, error), options FallbackOptions) *FallbackWriter {
+	if options.MemorySize <= 0 {
+		options.MemorySize = defaultFallbackMemorySize
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = defaultFallbackRetryInterval
+	}
+	return &FallbackWriter{
+		name:    name,
+		out:     out,
+		reopen:  reopen,
+		options: options,
+	}
+}
+
+// Write writes p to the log file, or to the fallback if the log file is
+// unwritable. Errors are never returned, so logrus will not report them.
+func (w *FallbackWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fallback == nil {
+		_, err := w.out.Write(p)
+		if err == nil {
+			return len(p), nil
+		}
+		w.fail(err)
+	} else if time.Since(w.lastRetry) >= w.options.RetryInterval && w.recover(p) {
+		return len(p), nil
+	}
+
+	w.diverted++
+	if w.options.Policy == FallbackMemory {
+		w.remember(p)
+	} else {
+		w.fallback.Write(p)
+	}
+	return len(p), nil
+}
+
+// fail warns on console once, and switches to the fallback
+func (w *FallbackWriter) fail(err error) {
+	w.lastRetry = time.Now()
+	target := "discard"
+
+	switch w.options.Policy {
+	case FallbackMemory:
+		target = "memory"
+		w.fallback = ioutil.Discard
+	case FallbackFile:
+		if out, ferr := openLogOutput(w.options.File); ferr == nil {
+			target = w.options.File
+			w.fallback = out
+		} else {
+			w.fallback = ioutil.Discard
+		}
+	default:
+		w.fallback = ioutil.Discard
+	}
+
+	fmt.Fprintf(o.stderr, "WARNING: fail to write logfile '%s': %s, switch to %s\n",
+		w.name, err, target)
+}
+
+// recover reopens the log file, writes entries kept in memory and p, and
+// switches back to the log file if success
+func (w *FallbackWriter) recover(p []byte) bool {
+	w.lastRetry = time.Now()
+	out, err := w.reopen()
+	if err != nil {
+		return false
+	}
+
+	for _, data := range append(w.memory, p) {
+		if _, err = out.Write(data); err != nil {
+			closeWriter(out)
+			return false
+		}
+	}
+
+	closeWriter(w.out)
+	if w.fallback != ioutil.Discard {
+		closeWriter(w.fallback)
+	}
+	w.out = out
+	w.fallback = nil
+	w.memory = nil
+	w.memSize = 0
+	fmt.Fprintf(o.stderr, "WARNING: logfile '%s' is recovered, %d entries were diverted\n",
+		w.name, w.diverted)
+	return true
+}
+
+// remember keeps a copy of p in memory, and drops the oldest entries if
+// exceeds MemorySize
+func (w *FallbackWriter) remember(p []byte) {
+	w.memory = append(w.memory, append([]byte(nil), p...))
+	w.memSize += len(p)
+	for w.memSize > w.options.MemorySize && len(w.memory) > 0 {
+		w.memSize -= len(w.memory[0])
+		w.memory = w.memory[1:]
+	}
+}
+
+// Sync syncs the log file, or the fallback if it is in use
+func (w *FallbackWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	out := w.out
+	if w.fallback != nil {
+		out = w.fallback
+	}
+	if s, ok := out.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Diverted returns number of entries written to the fallback
+func (w *FallbackWriter) Diverted() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.diverted
+}
+
+func closeWriter(w io.Writer) {
+	if c, ok := w.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// Diverted returns number of entries of log file written to the fallback
+func Diverted() uint64 {
+	l := mLogger.FileLogger
+	if l == nil {
+		return 0
+	}
+	out := l.Out
+	if s, ok := l.Formatter.(*AsyncSink); ok {
+		out = s.out
+	}
+	if w, ok := out.(*SyncWriter); ok {
+		out = w.out
+	}
+	if w, ok := out.(*FallbackWriter); ok {
+		return w.Diverted()
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type brokenWriter struct {
+	bytes.Buffer
+	broken bool
+}
+
+func (w *brokenWriter) Write(p []byte) (int, error) {
+	if w.broken {
+		return 0, errors.New("no space left on device")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFallbackMemory(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		stderr: &buffer,
+	})
+	defer Init(Options{})
+
+	primary := &brokenWriter{}
+	reopened := &brokenWriter{broken: true}
+	w := NewFallbackWriter("log.txt", primary, func() (io.Writer, error) {
+		return reopened, nil
+	}, FallbackOptions{
+		Policy:        FallbackMemory,
+		MemorySize:    10,
+		RetryInterval: time.Millisecond,
+	})
+
+	w.Write([]byte("line 1\n"))
+	primary.broken = true
+	w.Write([]byte("line 2\n"))
+	w.Write([]byte("line 3\n"))
+	time.Sleep(2 * time.Millisecond)
+	w.Write([]byte("line 4\n"))
+	assert.Equal(uint64(3), w.Diverted())
+
+	reopened.broken = false
+	time.Sleep(2 * time.Millisecond)
+	w.Write([]byte("line 5\n"))
+	w.Write([]byte("line 6\n"))
+	assert.Equal(uint64(3), w.Diverted())
+
+	assert.Equal("line 1\n", primary.String())
+	// Only the last entry is kept in memory
+	assert.Equal("line 4\nline 5\nline 6\n", reopened.String())
+
+	expect = `WARNING: fail to write logfile 'log.txt': no space left on device, switch to memory
+WARNING: logfile 'log.txt' is recovered, 3 entries were diverted
+`
+	assert.Equal(expect, buffer.String())
+}
+
+func TestFallbackFile(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+	fallbackLog := filepath.Join(tmpdir, "fallback.txt")
+
+	Init(Options{
+		LogFile: tmpLog,
+		Fallback: FallbackOptions{
+			Policy: FallbackFile,
+			File:   fallbackLog,
+		},
+		stderr: &buffer,
+	})
+	defer Init(Options{})
+
+	Warn("warn #1")
+	// Break log file
+	w := mLogger.FileLogger.Out.(*FallbackWriter)
+	closeWriter(w.out)
+	Error("error #2")
+	assert.Equal(uint64(1), Diverted())
+
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal("WARN[<time>]: warn #1\n", filterTime(string(data)))
+	data, err = ioutil.ReadFile(fallbackLog)
+	assert.Nil(err)
+	assert.Equal("ERRO[<time>]: error #2\n", filterTime(string(data)))
+	assert.Contains(buffer.String(), "switch to "+fallbackLog+"\n")
+}
@@ -0,0 +1,12 @@
+// +build windows plan9 js
+
+package log
+
+import (
+	"os"
+)
+
+// fileOwner is not supported, because files have no uid
+func fileOwner(finfo os.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,17 @@
+// +build !windows,!plan9,!js
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns uid of the owner of file
+func fileOwner(finfo os.FileInfo) (int, bool) {
+	stat, ok := finfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), true
+}
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"io/ioutil"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FlightRecorder keeps recent entries in a ring buffer, and dumps them into
+// the target logger before an error is written, so we can see what led up
+// to a failure without always writing debug logs.
+//
+// FlightRecorder is a formatter which records entries, and is also a hook
+// of the target logger which triggers the dump.
+type FlightRecorder struct {
+	mu      sync.Mutex
+	target  *logrus.Logger
+	entries []*logrus.Entry
+	next    int
+	count   int
+}
+
+// NewFlightRecorder creates a logger which records the last size entries at
+// or above level, and dumps them into target when an error is logged.
+func NewFlightRecorder(size int, level logrus.Level, target *logrus.Logger) *logrus.Logger {
+	recorder := &FlightRecorder{
+		target:  target,
+		entries: make([]*logrus.Entry, size),
+	}
+	target.AddHook(recorder)
+
+	return &logrus.Logger{
+		Out:          ioutil.Discard,
+		Formatter:    recorder,
+		Hooks:        make(logrus.LevelHooks),
+		Level:        level,
+		ExitFunc:     func(int) {},
+		ReportCaller: false,
+	}
+}
+
+// Format saves a copy of entry into the ring buffer, and outputs nothing.
+// Entries which the target writes are not saved, so the ring buffer only
+// keeps entries missing from the target.
+func (r *FlightRecorder) Format(entry *logrus.Entry) ([]byte, error) {
+	if r.target.IsLevelEnabled(entry.Level) {
+		return nil, nil
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	e := &logrus.Entry{
+		Data:    data,
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Caller:  entry.Caller,
+		Message: entry.Message,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return nil, nil
+	}
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.count < len(r.entries) {
+		r.count++
+	}
+	return nil, nil
+}
+
+// Levels returns levels which trigger the dump
+func (r *FlightRecorder) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.PanicLevel,
+		logrus.FatalLevel,
+		logrus.ErrorLevel,
+	}
+}
+
+// Fire dumps recorded entries into the target logger. It is called with
+// the lock of target held, before the error entry is written.
+func (r *FlightRecorder) Fire(entry *logrus.Entry) error {
+	for _, e := range r.takeEntries() {
+		// Entries which the target has already written are skipped
+		if r.target.IsLevelEnabled(e.Level) {
+			continue
+		}
+		e.Logger = r.target
+		serialized, err := r.target.Formatter.Format(e)
+		if err != nil {
+			return err
+		}
+		if _, err = r.target.Out.Write(serialized); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// takeEntries returns recorded entries from oldest to newest, and clears
+// the ring buffer.
+func (r *FlightRecorder) takeEntries() []*logrus.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]*logrus.Entry, 0, r.count)
+	start := r.next - r.count
+	if start < 0 {
+		start += len(r.entries)
+	}
+	for i := 0; i < r.count; i++ {
+		idx := (start + i) % len(r.entries)
+		entries = append(entries, r.entries[idx])
+		r.entries[idx] = nil
+	}
+	r.next = 0
+	r.count = 0
+	return entries
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogLevel:           "warning",
+		LogFile:            tmpLog,
+		FlightRecorderSize: 3,
+		stderr:             &buffer,
+	})
+	defer Init(Options{})
+
+	Debug("debug #0")
+	Debug("debug #1")
+	Trace("trace #2")
+	Info("info #3")
+	Warn("warn #4")
+	Error("error #5")
+	Debug("debug #6")
+	Error("error #7")
+
+	expect = `WARNING: warn #4
+ERROR: error #5
+ERROR: error #7
+`
+	assert.Equal(expect, buffer.String())
+
+	// Warnings do not take slots of the ring buffer
+	expect = `WARN[<time>]: warn #4
+DEBU[<time>]: debug #1
+TRAC[<time>]: trace #2
+INFO[<time>]: info #3
+ERRO[<time>]: error #5
+DEBU[<time>]: debug #6
+ERRO[<time>]: error #7
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+}
@@ -0,0 +1,103 @@
+package formatter
+
+import (
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// CallerPath defines how to show file path of caller
+type CallerPath int
+
+const (
+	// CallerPathFull shows the full path of the source file
+	CallerPathFull CallerPath = iota
+	// CallerPathModule shows path relative to the main module, and the
+	// path of files in other modules starts with their package path
+	CallerPathModule
+	// CallerPathBase shows only the base name of the source file
+	CallerPathBase
+)
+
+var (
+	mainModulePath  string
+	mainPackagePath string
+	buildInfoOnce   sync.Once
+)
+
+// mainModule returns module path of main package
+func mainModule() string {
+	buildInfoOnce.Do(readBuildInfo)
+	return mainModulePath
+}
+
+// mainPackage returns package path of main package, such as
+// "github.com/user/repo/cmd/app"
+func mainPackage() string {
+	buildInfoOnce.Do(readBuildInfo)
+	return mainPackagePath
+}
+
+func readBuildInfo() {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		mainModulePath = info.Main.Path
+		mainPackagePath = info.Path
+	}
+}
+
+// PackagePath returns package path of a full function name, such as
+// "github.com/user/repo/pkg" of "github.com/user/repo/pkg.(*T).Method"
+func PackagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
+
+// shortFunction removes directories of package path from function name,
+// such as "pkg.(*T).Method" of "github.com/user/repo/pkg.(*T).Method"
+func shortFunction(function string) string {
+	return function[strings.LastIndex(function, "/")+1:]
+}
+
+// CallerFile returns file path of frame according to style
+func CallerFile(frame *runtime.Frame, style CallerPath) string {
+	switch style {
+	case CallerPathBase:
+		return filepath.Base(frame.File)
+	case CallerPathModule:
+		if frame.Function == "" {
+			return frame.File
+		}
+		pkg := PackagePath(frame.Function)
+		if pkg == "main" {
+			// Functions of main package are named "main.*", and the
+			// package path is known only if it is in the main module
+			pkg = mainPackage()
+			module := mainModule()
+			if module == "" || pkg != module && !strings.HasPrefix(pkg, module+"/") {
+				return frame.File
+			}
+		}
+		name := pkg + "/" + filepath.Base(frame.File)
+		if module := mainModule(); module != "" {
+			name = strings.TrimPrefix(name, module+"/")
+		}
+		return name
+	default:
+		return frame.File
+	}
+}
+
+// CallerFunction returns function name of frame, without directories of
+// package path if short is true
+func CallerFunction(frame *runtime.Frame, short bool) string {
+	if short {
+		return shortFunction(frame.Function)
+	}
+	return frame.Function
+}
@@ -0,0 +1,133 @@
+package formatter
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorChain returns messages of err and all errors it wraps, using
+// "Unwrap() error" and "Unwrap() []error" (errors.Join) depth first.
+// Wrappers with the same message as the error they wrap, such as
+// wrappers which only attach a stack trace, are skipped.
+func ErrorChain(err error) []string {
+	var chain []string
+	walkErrors(err, func(e error) bool {
+		msg := e.Error()
+		if len(chain) == 0 || chain[len(chain)-1] != msg {
+			chain = append(chain, msg)
+		}
+		return true
+	})
+	return chain
+}
+
+// ErrorStack returns the stack trace attached to err or the first error it
+// wraps which has one. A stack trace is provided by a "StackTrace()" method
+// which returns a slice of program counters, such as StackTrace() of
+// github.com/pkg/errors. Each frame is formatted as "function (file:line)".
+func ErrorStack(err error) []string {
+	var pcs []uintptr
+	walkErrors(err, func(e error) bool {
+		pcs = StackTrace(e)
+		return pcs == nil
+	})
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var stack []string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// walkErrors calls fn for err and errors it wraps, until fn returns false
+func walkErrors(err error, fn func(error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkErrors(e.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if !walkErrors(inner, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// StackTrace calls "StackTrace()" method of err using reflection, so we do
+// not depend on the package which defines the type of stack trace. Errors
+// it wraps are not checked.
+func StackTrace(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	t := m.Type().Out(0)
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return pcs
+}
+
+// ErrorDetails is the structured form of an error for machine formats,
+// such as JSON
+type ErrorDetails struct {
+	Error  string   `json:"error"`
+	Causes []string `json:"causes,omitempty"`
+	Stack  []string `json:"stack,omitempty"`
+}
+
+// NewErrorDetails returns message, causes and stack trace of err
+func NewErrorDetails(err error) ErrorDetails {
+	details := ErrorDetails{
+		Error: err.Error(),
+		Stack: ErrorStack(err),
+	}
+	if chain := ErrorChain(err); len(chain) > 1 {
+		details.Causes = chain[1:]
+	}
+	return details
+}
+
+// StructuredErrorFormatter wraps a formatter, such as logrus.JSONFormatter,
+// and replaces error fields with ErrorDetails, so causes and stack trace
+// are rendered as arrays instead of only the message of error.
+type StructuredErrorFormatter struct {
+	Formatter logrus.Formatter
+}
+
+// Format renders entry with error fields replaced by ErrorDetails
+func (f *StructuredErrorFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	e := *entry
+	e.Data = make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok && err != nil {
+			v = NewErrorDetails(err)
+		}
+		e.Data[k] = v
+	}
+	return f.Formatter.Format(&e)
+}
@@ -0,0 +1,23 @@
+package formatter
+
+import (
+	"regexp"
+)
+
+// MultiLine defines how to show continuation lines of multi-line messages
+type MultiLine int
+
+const (
+	// MultiLineRaw shows continuation lines as is, from column zero
+	MultiLineRaw MultiLine = iota
+	// MultiLineIndent indents continuation lines to align with the first
+	// line of message
+	MultiLineIndent
+	// MultiLinePrefix repeats level and timestamp prefix on continuation
+	// lines
+	MultiLinePrefix
+)
+
+var (
+	colorPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
@@ -0,0 +1,56 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SafeMode defines how to protect log from lines forged by messages and
+// field values with embedded newlines and control characters
+type SafeMode int
+
+const (
+	// SafeModeOff writes messages and field values as is
+	SafeModeOff SafeMode = iota
+	// SafeModeEscape escapes newlines and other control characters, such
+	// as "\n" and "\x1b", so each entry is written in one line
+	SafeModeEscape
+	// SafeModeIndent keeps newlines, but starts continuation lines with
+	// ContinuationMarker, and escapes other control characters
+	SafeModeIndent
+)
+
+// ContinuationMarker starts continuation lines of an entry in SafeModeIndent
+const ContinuationMarker = "    | "
+
+// isUnsafeRune checks if r may break or forge lines of log
+func isUnsafeRune(r rune) bool {
+	return r != '\t' && (unicode.IsControl(r) || r == '\u2028' || r == '\u2029')
+}
+
+// Sanitize escapes newlines and control characters of s according to mode
+func Sanitize(s string, mode SafeMode) string {
+	if mode == SafeModeOff || strings.IndexFunc(s, isUnsafeRune) < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n' && mode == SafeModeIndent:
+			b.WriteString("\n" + ContinuationMarker)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case !isUnsafeRune(r):
+			b.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	return b.String()
+}
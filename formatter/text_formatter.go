@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
 )
@@ -28,6 +29,9 @@ const (
 	defaultTimestampFormat = time.RFC3339
 )
 
+// Fields are shown after message padded to fieldsColumn characters
+const fieldsColumn = 44
+
 // Global variables
 var (
 	BaseTimestamp time.Time
@@ -73,6 +77,20 @@ type TextFormatter struct {
 	// QuoteEmptyFields will wrap empty fields in quotes if true
 	QuoteEmptyFields bool
 
+	// CallerPath defines how to show file path of caller
+	CallerPath CallerPath
+
+	// ShortFunctionName shows function name of caller without directories
+	// of package path
+	ShortFunctionName bool
+
+	// SafeMode escapes newlines and control characters in messages and
+	// field values, so they cannot forge entries
+	SafeMode SafeMode
+
+	// MultiLine defines how to show continuation lines of messages
+	MultiLine MultiLine
+
 	// Whether the logger's out is to a terminal
 	isTerminal bool
 
@@ -175,6 +193,7 @@ func (f *TextFormatter) printEntry(b *bytes.Buffer, entry *logrus.Entry) {
 	)
 
 	colorSet, colorReset = f.GetColors(entry.Level.String())
+	ResolveFields(entry.Data)
 
 	levelText = strings.ToUpper(entry.Level.String())
 	if !f.DisableLevelTruncation {
@@ -184,47 +203,51 @@ func (f *TextFormatter) printEntry(b *bytes.Buffer, entry *logrus.Entry) {
 	// Remove a single newline if it already exists in the message to keep
 	// the behavior of logrus text_formatter the same as the stdlib log package
 	entry.Message = strings.TrimSuffix(entry.Message, "\n")
+	entry.Message = Sanitize(entry.Message, f.SafeMode)
 
 	if entry.HasCaller() {
 		caller = fmt.Sprintf("%s:%d %s() ",
-			entry.Caller.File, entry.Caller.Line, entry.Caller.Function)
+			CallerFile(entry.Caller, f.CallerPath),
+			entry.Caller.Line,
+			CallerFunction(entry.Caller, f.ShortFunctionName))
 	}
 
-	w := 0
-	if len(entry.Data) > 0 {
-		w = -44
-	}
-	if f.DisableTimestamp {
-		fmt.Fprintf(b, "%s%s:%s %s%*s",
+	var prefix string
+	if f.DisableTimestamp || entry.Time.IsZero() {
+		prefix = fmt.Sprintf("%s%s:%s ",
 			colorSet,
 			levelText,
 			colorReset,
-			caller,
-			w,
-			entry.Message,
 		)
 	} else if !f.FullTimestamp {
-		fmt.Fprintf(b, "%s%s[%04d]:%s %s%*s",
+		prefix = fmt.Sprintf("%s%s[%04d]:%s ",
 			colorSet,
 			levelText,
 			int(entry.Time.Sub(BaseTimestamp)/time.Second),
 			colorReset,
-			caller,
-			w,
-			entry.Message,
 		)
 	} else {
-		fmt.Fprintf(b, "%s%s[%s]:%s %s%*s",
+		prefix = fmt.Sprintf("%s%s[%s]:%s ",
 			colorSet,
 			levelText,
 			entry.Time.Format(f.TimestampFormat),
 			colorReset,
-			caller,
-			w,
-			entry.Message,
 		)
 	}
 
+	message := f.FormatMultiLine(prefix, caller, entry.Message)
+	b.WriteString(prefix)
+	b.WriteString(caller)
+	b.WriteString(message)
+
+	// Align fields after the last line of message
+	if len(entry.Data) > 0 {
+		last := entry.Message[strings.LastIndex(entry.Message, "\n")+1:]
+		if n := utf8.RuneCountInString(last); n < fieldsColumn {
+			b.WriteString(strings.Repeat(" ", fieldsColumn-n))
+		}
+	}
+
 	if len(entry.Data) > 0 {
 		var keys []string
 		for k := range entry.Data {
@@ -235,14 +258,16 @@ func (f *TextFormatter) printEntry(b *bytes.Buffer, entry *logrus.Entry) {
 		}
 		if f.IsColored() {
 			for _, k := range keys {
-				fmt.Fprintf(b, " %s%s%s=", colorSet, k, colorReset)
+				fmt.Fprintf(b, " %s%s%s=", colorSet, Sanitize(k, f.SafeMode), colorReset)
 				f.appendValue(b, entry.Data[k])
 			}
 		} else {
 			b.WriteString(" (")
 			i := 0
 			for _, k := range keys {
-				fmt.Fprintf(b, "%s=%s", k, entry.Data[k])
+				fmt.Fprintf(b, "%s=%s",
+					Sanitize(k, f.SafeMode),
+					Sanitize(fmt.Sprint(entry.Data[k]), f.SafeMode))
 				if i != len(entry.Data)-1 {
 					b.WriteString(" ")
 				}
@@ -250,6 +275,48 @@ func (f *TextFormatter) printEntry(b *bytes.Buffer, entry *logrus.Entry) {
 			}
 			b.WriteString(")")
 		}
+
+		for _, k := range keys {
+			if err, ok := entry.Data[k].(error); ok {
+				f.appendErrorDetails(b, err)
+			}
+		}
+	}
+}
+
+// FormatMultiLine returns msg whose continuation lines start with a hanging
+// indent or with prefix according to MultiLine. Prefix and caller are
+// shown before the first line of msg.
+func (f *TextFormatter) FormatMultiLine(prefix, caller, msg string) string {
+	if f.MultiLine == MultiLineRaw || !strings.Contains(msg, "\n") {
+		return msg
+	}
+
+	var continuation string
+	switch f.MultiLine {
+	case MultiLinePrefix:
+		continuation = prefix
+	default:
+		width := utf8.RuneCountInString(colorPattern.ReplaceAllString(prefix+caller, ""))
+		continuation = strings.Repeat(" ", width)
+	}
+	return strings.Replace(msg, "\n", "\n"+continuation, -1)
+}
+
+// appendErrorDetails renders causes and stack trace of err as indented
+// continuation lines. In SafeModeEscape, newlines between them are escaped
+// too, so the entry is still written in one line.
+func (f *TextFormatter) appendErrorDetails(b *bytes.Buffer, err error) {
+	newline := "\n"
+	if f.SafeMode == SafeModeEscape {
+		newline = `\n`
+	}
+	chain := ErrorChain(err)
+	for i := 1; i < len(chain); i++ {
+		fmt.Fprintf(b, "%s    caused by: %s", newline, Sanitize(chain[i], f.SafeMode))
+	}
+	for _, frame := range ErrorStack(err) {
+		fmt.Fprintf(b, "%s    at %s", newline, Sanitize(frame, f.SafeMode))
 	}
 }
 
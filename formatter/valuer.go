@@ -0,0 +1,42 @@
+package formatter
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Valuer is implemented by field values which are evaluated only when the
+// entry is written
+type Valuer interface {
+	Value() interface{}
+}
+
+// ResolveValue evaluates v if it is a Valuer or a func() interface{}
+func ResolveValue(v interface{}) (interface{}, bool) {
+	switch value := v.(type) {
+	case Valuer:
+		return value.Value(), true
+	case func() interface{}:
+		return value(), true
+	}
+	return v, false
+}
+
+// HasLazyValue checks if any field value needs to be evaluated
+func HasLazyValue(fields logrus.Fields) bool {
+	for _, v := range fields {
+		switch v.(type) {
+		case Valuer, func() interface{}:
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveFields evaluates lazy values of fields in place
+func ResolveFields(fields logrus.Fields) {
+	for k, v := range fields {
+		if value, ok := ResolveValue(v); ok {
+			fields[k] = value
+		}
+	}
+}
@@ -3,8 +3,10 @@ module github.com/jiangxin/multi-log
 go 1.12
 
 require (
+	github.com/go-logr/logr v1.2.4
 	github.com/konsorten/go-windows-terminal-sequences v1.0.2
 	github.com/sirupsen/logrus v1.4.0
 	github.com/stretchr/testify v1.2.2
 	golang.org/x/crypto v0.0.0-20180904163835-0709b304e793
+	golang.org/x/sys v0.0.0-20190412213103-97732733099d
 )
@@ -0,0 +1,23 @@
+package log
+
+// GrpcLogger implements grpclog.LoggerV2 backed by MultiLogger, so the
+// diagnostics of gRPC are shown on console and saved in log file like
+// other messages. Install it using:
+//
+//     grpclog.SetLoggerV2(log.NewGrpcLogger())
+type GrpcLogger struct {
+	*MultiLogger
+}
+
+// NewGrpcLogger creates a grpclog.LoggerV2 backed by MultiLogger
+func NewGrpcLogger() *GrpcLogger {
+	return &GrpcLogger{
+		MultiLogger: &mLogger,
+	}
+}
+
+// V reports whether verbosity level l is enabled. V(0) is info, V(1) is
+// debug, and V(2) or above is trace.
+func (g *GrpcLogger) V(l int) bool {
+	return g.IsLevelEnabled(verboseLevel(l))
+}
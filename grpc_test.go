@@ -0,0 +1,58 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// grpcLoggerV2 is the same as grpclog.LoggerV2
+type grpcLoggerV2 interface {
+	Info(args ...interface{})
+	Infoln(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningln(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorln(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalln(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	V(l int) bool
+}
+
+func TestGrpcLogger(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		logger grpcLoggerV2
+		expect string
+	)
+
+	Init(Options{
+		Verbose:  1,
+		stderr:   &buffer,
+		exitFunc: testingExitFunc,
+	})
+	defer Init(Options{})
+
+	logger = NewGrpcLogger()
+	logger.Infof("info #%d", 1)
+	logger.Warningln("warning #", 2)
+	logger.Error("error #", 3)
+	logger.Fatal("fatal #", 4)
+
+	assert.True(logger.V(0))
+	assert.False(logger.V(1))
+	assert.False(logger.V(2))
+
+	expect = `INFO: info #1
+WARNING: warning # 2
+ERROR: error #3
+FATAL: fatal #4
+`
+	assert.Equal(expect, buffer.String())
+}
@@ -0,0 +1,215 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultJournalSocket = "/run/systemd/journal/socket"
+
+	// Entries larger than this size are passed to journald using memfd
+	defaultJournalMaxDatagram = 128 * 1024
+)
+
+// JournalFormatter formats entries using the journald native protocol
+type JournalFormatter struct {
+	// Identifier is used as SYSLOG_IDENTIFIER, default is program name
+	Identifier string
+}
+
+// Format renders a single log entry as journal fields
+func (f *JournalFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b *bytes.Buffer
+	if entry.Buffer != nil {
+		b = entry.Buffer
+	} else {
+		b = &bytes.Buffer{}
+	}
+
+	identifier := f.Identifier
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	appendJournalField(b, "MESSAGE", strings.TrimSuffix(entry.Message, "\n"))
+	appendJournalField(b, "PRIORITY", strconv.Itoa(journalPriority(entry.Level)))
+	appendJournalField(b, "SYSLOG_IDENTIFIER", identifier)
+	if entry.HasCaller() {
+		appendJournalField(b, "CODE_FILE", entry.Caller.File)
+		appendJournalField(b, "CODE_LINE", strconv.Itoa(entry.Caller.Line))
+		appendJournalField(b, "CODE_FUNC", entry.Caller.Function)
+	}
+
+	formatter.ResolveFields(entry.Data)
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := journalFieldName(k)
+		if name == "" {
+			continue
+		}
+		var value string
+		switch v := entry.Data[k].(type) {
+		case string:
+			value = v
+		case error:
+			value = v.Error()
+			// Causes and stack frames are repeated fields
+			for _, cause := range formatter.ErrorChain(v)[1:] {
+				appendJournalField(b, name+"_CAUSE", cause)
+			}
+			for _, frame := range formatter.ErrorStack(v) {
+				appendJournalField(b, name+"_STACK", frame)
+			}
+		default:
+			value = fmt.Sprint(v)
+		}
+		appendJournalField(b, name, value)
+	}
+
+	return b.Bytes(), nil
+}
+
+// journalPriority maps logrus level to syslog priority
+func journalPriority(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 0
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// journalFieldName converts key to a valid journal field name, which only
+// contains uppercase letters, digits and underscores, and does not start
+// with an underscore or a digit.
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if !((c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_') {
+			name[i] = '_'
+		}
+	}
+	s := strings.TrimLeft(string(name), "_0123456789")
+	if len(s) > 64 {
+		s = s[:64]
+	}
+	return s
+}
+
+func appendJournalField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+
+	// Binary safe format: name, newline, 64-bit little endian size, value
+	b.WriteString(name)
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalWriter sends each write as one datagram to journald
+type journalWriter struct {
+	conn        *net.UnixConn
+	addr        *net.UnixAddr
+	maxDatagram int
+}
+
+func newJournalWriter(socket string) (*journalWriter, error) {
+	if socket == "" {
+		socket = defaultJournalSocket
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return nil, fmt.Errorf("fail to find journald socket: %s", err)
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: "", Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("fail to connect to journald: %s", err)
+	}
+	return &journalWriter{
+		conn:        conn,
+		addr:        &net.UnixAddr{Name: socket, Net: "unixgram"},
+		maxDatagram: defaultJournalMaxDatagram,
+	}, nil
+}
+
+func (w *journalWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.maxDatagram {
+		_, _, err := w.conn.WriteMsgUnix(p, nil, w.addr)
+		if err == nil {
+			return len(p), nil
+		}
+		if !isMessageTooLarge(err) {
+			return 0, err
+		}
+	}
+
+	// Too large for one datagram, pass it using a file descriptor
+	if err := journalSendFd(w.conn, w.addr, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *journalWriter) Close() error {
+	return w.conn.Close()
+}
+
+func isMessageTooLarge(err error) bool {
+	if e, ok := err.(*net.OpError); ok {
+		err = e.Err
+	}
+	if e, ok := err.(*os.SyscallError); ok {
+		err = e.Err
+	}
+	return err == syscall.EMSGSIZE || err == syscall.ENOBUFS
+}
+
+// NewJournalLogger creates a logger which writes to journald using the
+// native protocol. If socket is empty, the default journald socket is used.
+func NewJournalLogger(socket string, level logrus.Level) (*logrus.Logger, error) {
+	w, err := newJournalWriter(socket)
+	if err != nil {
+		return nil, err
+	}
+	logger := &logrus.Logger{
+		Out:          w,
+		Formatter:    &JournalFormatter{},
+		Hooks:        make(logrus.LevelHooks),
+		Level:        level,
+		ExitFunc:     func(int) {},
+		ReportCaller: true,
+	}
+	// CODE_FILE, CODE_LINE and CODE_FUNC are always sent to journald
+	logger.AddHook(&callerHook{})
+	return logger, nil
+}
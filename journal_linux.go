@@ -0,0 +1,34 @@
+// +build linux
+
+package log
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// journalSendFd writes data into a sealed memfd, and passes the file
+// descriptor to journald.
+func journalSendFd(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	fd, err := unix.MemfdCreate("journal-entry", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return fmt.Errorf("fail to create memfd: %s", err)
+	}
+	file := os.NewFile(uintptr(fd), "journal-entry")
+	defer file.Close()
+
+	if _, err = file.Write(data); err != nil {
+		return err
+	}
+	_, err = unix.FcntlInt(file.Fd(), unix.F_ADD_SEALS,
+		unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL)
+	if err != nil {
+		return fmt.Errorf("fail to seal memfd: %s", err)
+	}
+
+	_, _, err = conn.WriteMsgUnix(nil, unix.UnixRights(int(file.Fd())), addr)
+	return err
+}
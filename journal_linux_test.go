@@ -0,0 +1,168 @@
+// +build linux
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeJournal struct {
+	conn *net.UnixConn
+}
+
+func newFakeJournal(socket string) (*fakeJournal, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &fakeJournal{conn: conn}, nil
+}
+
+// receive reads one datagram, and decodes it using the native protocol.
+// If a file descriptor is passed, entry is read from the file.
+func (j *fakeJournal) receive() (map[string]string, bool, error) {
+	var (
+		buf   = make([]byte, 1024*1024)
+		oob   = make([]byte, 1024)
+		data  []byte
+		useFd bool
+	)
+
+	j.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, oobn, _, _, err := j.conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, false, err
+	}
+	data = buf[:n]
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			return nil, false, err
+		}
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		if err != nil {
+			return nil, false, err
+		}
+		file := os.NewFile(uintptr(fds[0]), "memfd")
+		defer file.Close()
+		file.Seek(0, io.SeekStart)
+		data, err = ioutil.ReadAll(file)
+		if err != nil {
+			return nil, false, err
+		}
+		useFd = true
+	}
+
+	fields := make(map[string]string)
+	r := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, false, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if i := strings.Index(line, "="); i >= 0 {
+			fields[line[:i]] = line[i+1:]
+			continue
+		}
+		var size uint64
+		if err = binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, false, err
+		}
+		value := make([]byte, size+1)
+		if _, err = io.ReadFull(r, value); err != nil {
+			return nil, false, err
+		}
+		fields[line] = string(value[:size])
+	}
+	return fields, useFd, nil
+}
+
+func TestJournalLogger(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	socket := filepath.Join(tmpdir, "socket")
+	journal, err := newFakeJournal(socket)
+	if !assert.Nil(err) {
+		return
+	}
+	defer journal.conn.Close()
+
+	Init(Options{
+		LogLevel:      "info",
+		Journald:      true,
+		stderr:        &buffer,
+		journalSocket: socket,
+	})
+	defer Init(Options{})
+	assert.Equal(1, len(mLogger.Sinks))
+
+	Debug("debug is filtered")
+	WithFields(map[string]interface{}{
+		"request-id": "1234",
+		"_private":   "yes",
+	}).Warnf("warn #%d", 1)
+
+	fields, useFd, err := journal.receive()
+	assert.Nil(err)
+	assert.False(useFd)
+	assert.Equal("warn #1", fields["MESSAGE"])
+	assert.Equal("4", fields["PRIORITY"])
+	assert.Equal("1234", fields["REQUEST_ID"])
+	assert.Equal("yes", fields["PRIVATE"])
+	assert.NotEmpty(fields["SYSLOG_IDENTIFIER"])
+
+	_, file, line, _ := runtime.Caller(0)
+	Error("multiple\nlines")
+	fields, useFd, err = journal.receive()
+	assert.Nil(err)
+	assert.False(useFd)
+	assert.Equal("multiple\nlines", fields["MESSAGE"])
+	assert.Equal("3", fields["PRIORITY"])
+	assert.Equal(file, fields["CODE_FILE"])
+	assert.Equal(strconv.Itoa(line+1), fields["CODE_LINE"])
+	assert.Equal("github.com/jiangxin/multi-log.TestJournalLogger", fields["CODE_FUNC"])
+
+	// Large entry is passed using memfd
+	mLogger.Sinks[0].Out.(*journalWriter).maxDatagram = 1024
+	msg := strings.Repeat("x", 4096)
+	Error(msg)
+	fields, useFd, err = journal.receive()
+	assert.Nil(err)
+	assert.True(useFd)
+	assert.Equal(msg, fields["MESSAGE"])
+}
+
+func TestJournalFieldName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("REQUEST_ID", journalFieldName("request-id"))
+	assert.Equal("USER_NAME", journalFieldName("__user.name"))
+	assert.Equal("", journalFieldName("1__"))
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package log
+
+import (
+	"fmt"
+	"net"
+)
+
+func journalSendFd(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	return fmt.Errorf("entry too large for journald (%d bytes)", len(data))
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/jiangxin/multi-log/formatter"
 	"github.com/jiangxin/multi-log/path"
@@ -19,9 +20,66 @@ type Options struct {
 	LogFile       string
 	LogLevel      string
 	ForceColors   bool
-
-	stderr   io.Writer
-	exitFunc func(int)
+	// Journald sends entries to systemd-journald using LogLevel
+	Journald bool
+	// Async writes log file and journald in background
+	Async        bool
+	AsyncOptions AsyncOptions
+	// FlightRecorderSize is the number of recent entries kept in memory,
+	// which are dumped into log file when an error is logged
+	FlightRecorderSize  int
+	FlightRecorderLevel string
+	// Sampling limits messages with the same template for each level
+	Sampling map[logrus.Level]SamplingRule
+	// CollapseDuplicates collapses consecutive identical lines on console,
+	// and shows a repeat counter after CollapseTimeout
+	CollapseDuplicates bool
+	CollapseTimeout    time.Duration
+	// ErrorKey is the field key used by WithError, default is "error"
+	ErrorKey string
+	// ErrorStack captures stack trace in WithError if err has none
+	ErrorStack bool
+	// DefaultFields and AutoFields are added to every entry, and to log
+	// file and other sinks only if DefaultFieldsFileOnly is true
+	DefaultFields         map[string]interface{}
+	AutoFields            AutoField
+	DefaultFieldsFileOnly bool
+	// ReportCaller shows file, line and function of the caller on console,
+	// and FileReportCaller does the same for log file and other sinks
+	ReportCaller      bool
+	FileReportCaller  bool
+	CallerPath        formatter.CallerPath
+	ShortFunctionName bool
+	// SafeMode protects console and log file from entries forged by
+	// newlines and control characters in messages and field values
+	SafeMode formatter.SafeMode
+	// MultiLine defines how to show continuation lines of messages
+	MultiLine formatter.MultiLine
+	// AuditLog is a tamper-evident log file, whose entries are chained by
+	// hashes, or by HMAC if AuditKey is set. See VerifyAuditLog.
+	AuditLog string
+	AuditKey []byte
+	// FileMode and DirMode are permissions of new log files and their
+	// directories, default are 0644 and 0755. They are masked by umask,
+	// unless IgnoreUmask is true.
+	FileMode    os.FileMode
+	DirMode     os.FileMode
+	IgnoreUmask bool
+	// EncryptKey encrypts log file using AES-GCM, and must be 16, 24 or
+	// 32 bytes. See NewDecryptReader.
+	EncryptKey []byte
+	// Fallback defines where to write entries if log file is unwritable
+	Fallback FallbackOptions
+	// Sync defines when to fsync log file
+	Sync SyncOptions
+	// RecoverAction defines what to do after RecoverAndLog logs a panic
+	RecoverAction RecoverAction
+	// Redact redacts secrets in fields and messages of all outputs
+	Redact RedactRules
+
+	stderr        io.Writer
+	exitFunc      func(int)
+	journalSocket string
 }
 
 // Logger defines our custom basic logger interface
@@ -35,12 +93,17 @@ type Logger interface {
 type MultiLogger struct {
 	StdLogger  *logrus.Logger
 	FileLogger *logrus.Logger
-	self       Logger
+	// Sinks are extra loggers (such as journald) which receive all entries
+	Sinks []*logrus.Logger
+	self  Logger
 }
 
 const (
-	defaultLogRotateSize int64 = 20 * 1024 * 1024
-	defaultLogLevel            = "warning"
+	defaultLogRotateSize       int64 = 20 * 1024 * 1024
+	defaultLogLevel                  = "warning"
+	defaultFlightRecorderLevel       = "trace"
+	defaultFileMode                  = os.FileMode(0644)
+	defaultDirMode                   = os.FileMode(0755)
 )
 
 var (
@@ -48,33 +111,97 @@ var (
 	o       Options
 )
 
-func openLogfile(logFile string) (*os.File, error) {
-	var err error
+// resolveLogfile returns absolute path of logFile
+func resolveLogfile(logFile string) (string, error) {
+	if logFile == "" {
+		return logFile, nil
+	}
+	logFile, err := path.Abs(logFile)
+	if err != nil {
+		return "", fmt.Errorf("fail to resolve logfile: %s", err)
+	}
+	return logFile, nil
+}
+
+// logfile is the file abstraction of log files
+type logfile interface {
+	io.WriteCloser
+	Sync() error
+}
 
-	if logFile != "" {
-		logFile, err = path.Abs(logFile)
-		if err != nil {
-			return nil, fmt.Errorf("fail to resolve logfile: %s", err)
-		}
+// openFile opens log files, and is replaced in tests
+var openFile = func(name string, flag int, perm os.FileMode) (logfile, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func openLogfile(logFile string) (logfile, error) {
+	logFile, err := resolveLogfile(logFile)
+	if err != nil {
+		return nil, err
 	}
 
 	dirname := filepath.Dir(logFile)
 	if _, err := os.Stat(dirname); err != nil && os.IsNotExist(err) {
-		err = os.MkdirAll(dirname, 0755)
+		err = os.MkdirAll(dirname, o.DirMode)
 		if err != nil {
 			return nil, err
 		}
+		if o.IgnoreUmask {
+			os.Chmod(dirname, o.DirMode)
+		}
 	}
 
-	if o.LogRotateSize > 0 {
-		if finfo, err := os.Stat(logFile); err == nil && finfo.Size() > o.LogRotateSize {
+	flag := os.O_CREATE | os.O_RDWR | os.O_APPEND
+	finfo, err := os.Stat(logFile)
+	exists := err == nil
+	if exists {
+		checkLogfile(logFile, finfo)
+		if o.LogRotateSize > 0 && finfo.Size() > o.LogRotateSize {
 			// Save 1 backup
 			os.Rename(logFile, logFile+".1")
-			return os.OpenFile(logFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC|os.O_APPEND, 0644)
+			flag |= os.O_TRUNC
+			exists = false
 		}
 	}
 
-	return os.OpenFile(logFile, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	file, err := openFile(logFile, flag, o.FileMode)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && o.IgnoreUmask {
+		os.Chmod(logFile, o.FileMode)
+	}
+	return file, nil
+}
+
+// openLogOutput opens logFile, which is encrypted if EncryptKey is set
+func openLogOutput(logFile string) (io.Writer, error) {
+	file, err := openLogfile(logFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.EncryptKey) == 0 {
+		return file, nil
+	}
+	w, err := NewEncryptWriter(file, o.EncryptKey)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// checkLogfile warns if existing logFile can be read by other users,
+// which is not allowed by FileMode
+func checkLogfile(logFile string, finfo os.FileInfo) {
+	if finfo.Mode().Perm()&0004 != 0 && o.FileMode&0004 == 0 {
+		fmt.Fprintf(o.stderr, "WARNING: logfile '%s' is world-readable (mode %04o)\n",
+			logFile, finfo.Mode().Perm())
+	}
+	if uid, ok := fileOwner(finfo); ok && uid != os.Getuid() {
+		fmt.Fprintf(o.stderr, "WARNING: logfile '%s' is owned by another user (uid %d)\n",
+			logFile, uid)
+	}
 }
 
 // Init must run first to initialize logger
@@ -85,6 +212,11 @@ func Init(options Options) {
 		noExitFunc = func(code int) { return }
 	)
 
+	mSampler.stop()
+	mSampler = nil
+	mLogger.Flush()
+	closeAsyncSinks(&mLogger)
+
 	o = options
 	if o.LogRotateSize == 0 {
 		o.LogRotateSize = defaultLogRotateSize
@@ -92,9 +224,20 @@ func Init(options Options) {
 	if o.stderr == nil {
 		o.stderr = os.Stderr
 	}
+	if o.FileMode == 0 {
+		o.FileMode = defaultFileMode
+	}
+	if o.DirMode == 0 {
+		o.DirMode = defaultDirMode
+	}
 	if o.LogLevel == "" {
 		o.LogLevel = defaultLogLevel
 	}
+	if o.ErrorKey == "" {
+		o.ErrorKey = logrus.ErrorKey
+	}
+	errorKey = o.ErrorKey
+	captureErrorStack = o.ErrorStack
 
 	switch o.Verbose {
 	case 0:
@@ -107,13 +250,22 @@ func Init(options Options) {
 		logLevel = logrus.TraceLevel
 	}
 
+	var stdout io.Writer = o.stderr
+	if o.CollapseDuplicates {
+		stdout = newCollapseWriter(stdout, o.CollapseTimeout)
+	}
+
 	mLogger.StdLogger = &logrus.Logger{
-		Out: o.stderr,
+		Out: stdout,
 		Formatter: &formatter.TextFormatter{
 			DisableTimestamp:       true,
 			FullTimestamp:          false,
 			DisableLevelTruncation: true,
 			ForceColors:            o.ForceColors,
+			CallerPath:             o.CallerPath,
+			ShortFunctionName:      o.ShortFunctionName,
+			SafeMode:               o.SafeMode,
+			MultiLine:              o.MultiLine,
 		},
 
 		Hooks:        make(logrus.LevelHooks),
@@ -122,25 +274,36 @@ func Init(options Options) {
 		ReportCaller: false,
 	}
 
-	if o.LogFile != "" {
-		logLevel = logrus.ErrorLevel
-		if o.LogLevel != "" {
-			logLevel, err = logrus.ParseLevel(o.LogLevel)
-			if err != nil {
-				logLevel = logrus.ErrorLevel
-			}
+	logLevel = logrus.ErrorLevel
+	if o.LogLevel != "" {
+		logLevel, err = logrus.ParseLevel(o.LogLevel)
+		if err != nil {
+			logLevel = logrus.ErrorLevel
 		}
+	}
 
-		file, err := openLogfile(o.LogFile)
+	mLogger.FileLogger = nil
+	if o.LogFile != "" {
+		out, err := openLogOutput(o.LogFile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
 		} else {
+			if o.Fallback.Policy != FallbackNone {
+				logFile := o.LogFile
+				out = NewFallbackWriter(logFile, out, func() (io.Writer, error) {
+					return openLogOutput(logFile)
+				}, o.Fallback)
+			}
 			mLogger.FileLogger = &logrus.Logger{
-				Out: file,
+				Out: out,
 				Formatter: &formatter.TextFormatter{
 					DisableTimestamp:       false,
 					FullTimestamp:          true,
 					DisableLevelTruncation: false,
+					CallerPath:             o.CallerPath,
+					ShortFunctionName:      o.ShortFunctionName,
+					SafeMode:               o.SafeMode,
+					MultiLine:              o.MultiLine,
 				},
 				Hooks:        make(logrus.LevelHooks),
 				Level:        logLevel,
@@ -149,6 +312,89 @@ func Init(options Options) {
 			}
 		}
 	}
+
+	if mLogger.FileLogger != nil && o.Sync != (SyncOptions{}) {
+		mLogger.FileLogger.Out = NewSyncWriter(mLogger.FileLogger.Out, o.Sync, mLogger.FileLogger)
+	}
+
+	mLogger.Sinks = nil
+	if o.Journald {
+		journal, err := NewJournalLogger(o.journalSocket, logLevel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		} else {
+			mLogger.Sinks = append(mLogger.Sinks, journal)
+		}
+	}
+
+	if o.Async {
+		for _, l := range mLogger.loggers() {
+			if l != mLogger.StdLogger {
+				NewAsyncSink(l, o.AsyncOptions)
+			}
+		}
+	}
+
+	// Audit log is always written synchronously, so entries are never dropped
+	if o.AuditLog != "" {
+		audit, err := NewAuditLogger(o.AuditLog, o.AuditKey, logLevel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		} else {
+			mLogger.Sinks = append(mLogger.Sinks, audit)
+		}
+	}
+
+	if o.FlightRecorderSize > 0 && mLogger.FileLogger != nil {
+		if o.FlightRecorderLevel == "" {
+			o.FlightRecorderLevel = defaultFlightRecorderLevel
+		}
+		logLevel, err = logrus.ParseLevel(o.FlightRecorderLevel)
+		if err != nil {
+			logLevel = logrus.TraceLevel
+		}
+		mLogger.Sinks = append(mLogger.Sinks,
+			NewFlightRecorder(o.FlightRecorderSize, logLevel, mLogger.FileLogger))
+	}
+
+	if len(o.Sampling) > 0 {
+		mSampler = newSampler(o.Sampling)
+	}
+
+	timeHook := &entryTimeHook{}
+	for _, l := range mLogger.loggers() {
+		l.AddHook(timeHook)
+	}
+
+	if fields := defaultFields(o.DefaultFields, o.AutoFields); len(fields) > 0 {
+		hook := &defaultFieldsHook{fields: fields}
+		for _, l := range mLogger.loggers() {
+			if o.DefaultFieldsFileOnly && l == mLogger.StdLogger {
+				continue
+			}
+			l.AddHook(hook)
+		}
+	}
+
+	if len(o.Redact.Keys) > 0 || len(o.Redact.Values) > 0 {
+		hook := newRedactHook(o.Redact)
+		for _, l := range mLogger.loggers() {
+			l.AddHook(hook)
+		}
+	}
+
+	if o.ReportCaller || o.FileReportCaller {
+		hook := &callerHook{}
+		for _, l := range mLogger.loggers() {
+			if l == mLogger.StdLogger && !o.ReportCaller ||
+				l != mLogger.StdLogger && !o.FileReportCaller ||
+				l.ReportCaller {
+				continue
+			}
+			l.ReportCaller = true
+			l.AddHook(hook)
+		}
+	}
 }
 
 // Self is used for class override.
@@ -160,15 +406,79 @@ func (v *MultiLogger) Self() Logger {
 	return v
 }
 
-// Logf is the base function to show message with specific log level
-func (v *MultiLogger) Logf(level logrus.Level, format string, args ...interface{}) {
+// loggers returns all available loggers: console, file and extra sinks
+func (v *MultiLogger) loggers() []*logrus.Logger {
+	loggers := make([]*logrus.Logger, 0, 2+len(v.Sinks))
 	if v.StdLogger != nil {
-		v.StdLogger.Logf(level, format, args...)
+		loggers = append(loggers, v.StdLogger)
 	}
-
 	if v.FileLogger != nil {
-		v.FileLogger.Logf(level, format, args...)
+		loggers = append(loggers, v.FileLogger)
+	}
+	for _, l := range v.Sinks {
+		if l != nil {
+			loggers = append(loggers, l)
+		}
+	}
+	return loggers
+}
+
+// IsLevelEnabled checks if any logger will log entries of level
+func (v *MultiLogger) IsLevelEnabled(level logrus.Level) bool {
+	for _, l := range v.loggers() {
+		if l.IsLevelEnabled(level) {
+			return true
+		}
 	}
+	return false
+}
+
+// logEach calls fn with each logger. Logrus panics after an entry of
+// PanicLevel is written, so the panic is recovered until the entry is
+// written by all loggers and async sinks are flushed, and then raised again.
+func (v *MultiLogger) logEach(level logrus.Level, fn func(l *logrus.Logger)) {
+	if level > logrus.PanicLevel {
+		for _, l := range v.loggers() {
+			fn(l)
+		}
+		return
+	}
+
+	var p interface{}
+	for _, l := range v.loggers() {
+		func() {
+			defer func() {
+				if r := recover(); r != nil && p == nil {
+					p = r
+				}
+			}()
+			fn(l)
+		}()
+	}
+	v.Flush()
+	if p != nil {
+		panic(p)
+	}
+}
+
+// allow checks sampling rules for message with the template
+func (v *MultiLogger) allow(level logrus.Level, key string) bool {
+	return !v.IsLevelEnabled(level) || mSampler.allow(level, key)
+}
+
+// Logf is the base function to show message with specific log level
+func (v *MultiLogger) Logf(level logrus.Level, format string, args ...interface{}) {
+	if mSampler != nil && !v.allow(level, format) {
+		return
+	}
+	v.logf(level, format, args...)
+}
+
+// logf logs message without sampling
+func (v *MultiLogger) logf(level logrus.Level, format string, args ...interface{}) {
+	v.logEach(level, func(l *logrus.Logger) {
+		l.Logf(level, format, args...)
+	})
 }
 
 // Tracef is Logf with TraceLevel
@@ -215,13 +525,12 @@ func (v *MultiLogger) Panicf(format string, args ...interface{}) {
 
 // Log is the base function to show message with specific log level
 func (v *MultiLogger) Log(level logrus.Level, args ...interface{}) {
-	if v.StdLogger != nil {
-		v.StdLogger.Log(level, args...)
-	}
-
-	if v.FileLogger != nil {
-		v.FileLogger.Log(level, args...)
+	if mSampler != nil && !v.allow(level, fmt.Sprint(args...)) {
+		return
 	}
+	v.logEach(level, func(l *logrus.Logger) {
+		l.Log(level, args...)
+	})
 }
 
 // Trace is Log with TraceLevel
@@ -268,13 +577,12 @@ func (v *MultiLogger) Panic(args ...interface{}) {
 
 // Logln is the base function to show message with specific log level
 func (v *MultiLogger) Logln(level logrus.Level, args ...interface{}) {
-	if v.StdLogger != nil {
-		v.StdLogger.Logln(level, args...)
-	}
-
-	if v.FileLogger != nil {
-		v.FileLogger.Logln(level, args...)
+	if mSampler != nil && !v.allow(level, fmt.Sprint(args...)) {
+		return
 	}
+	v.logEach(level, func(l *logrus.Logger) {
+		l.Logln(level, args...)
+	})
 }
 
 // Traceln is Logln with TraceLevel
@@ -321,13 +629,7 @@ func (v *MultiLogger) Panicln(args ...interface{}) {
 
 // Logf is the base function to show message with specific log level
 func Logf(level logrus.Level, format string, args ...interface{}) {
-	if mLogger.StdLogger != nil {
-		mLogger.StdLogger.Logf(level, format, args...)
-	}
-
-	if mLogger.FileLogger != nil {
-		mLogger.FileLogger.Logf(level, format, args...)
-	}
+	mLogger.Logf(level, format, args...)
 }
 
 // Tracef is Logf with TraceLevel
@@ -374,13 +676,7 @@ func Panicf(format string, args ...interface{}) {
 
 // Log is the base function to show message with specific log level
 func Log(level logrus.Level, args ...interface{}) {
-	if mLogger.StdLogger != nil {
-		mLogger.StdLogger.Log(level, args...)
-	}
-
-	if mLogger.FileLogger != nil {
-		mLogger.FileLogger.Log(level, args...)
-	}
+	mLogger.Log(level, args...)
 }
 
 // Trace is Log with TraceLevel
@@ -427,13 +723,7 @@ func Panic(args ...interface{}) {
 
 // Logln is the base function to show message with specific log level
 func Logln(level logrus.Level, args ...interface{}) {
-	if mLogger.StdLogger != nil {
-		mLogger.StdLogger.Logln(level, args...)
-	}
-
-	if mLogger.FileLogger != nil {
-		mLogger.FileLogger.Logln(level, args...)
-	}
+	mLogger.Logln(level, args...)
 }
 
 // Traceln is Logln with TraceLevel
@@ -479,6 +769,7 @@ func Panicln(args ...interface{}) {
 }
 
 func callExitFunc(code int) {
+	Flush()
 	if o.exitFunc == nil {
 		os.Exit(code)
 	}
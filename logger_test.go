@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
@@ -559,3 +560,49 @@ func TestPanic(t *testing.T) {
 		wfLogger.Panicln("called", "panicln")
 	})
 }
+
+func TestLogfileMode(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("file mode is not supported on windows")
+	}
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "private", "log.txt")
+
+	Init(Options{
+		LogFile:     tmpLog,
+		FileMode:    0600,
+		DirMode:     0700,
+		IgnoreUmask: true,
+		stderr:      &buffer,
+	})
+	defer Init(Options{})
+
+	finfo, err := os.Stat(tmpLog)
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0600), finfo.Mode().Perm())
+	finfo, err = os.Stat(filepath.Dir(tmpLog))
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0700), finfo.Mode().Perm())
+	assert.Equal("", buffer.String())
+
+	// Existing logfile is not changed, but warned
+	assert.Nil(os.Chmod(tmpLog, 0644))
+	Init(Options{
+		LogFile:  tmpLog,
+		FileMode: 0600,
+		stderr:   &buffer,
+	})
+	assert.Equal(fmt.Sprintf("WARNING: logfile '%s' is world-readable (mode 0644)\n", tmpLog),
+		buffer.String())
+}
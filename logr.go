@@ -0,0 +1,118 @@
+package log
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+)
+
+// LogSink implements logr.LogSink backed by MultiLogger. V-levels are
+// mapped like the Verbose option: V(0) is info, V(1) is debug, and V(2)
+// or above is trace.
+type LogSink struct {
+	name   string
+	fields logrus.Fields
+}
+
+var _ logr.LogSink = &LogSink{}
+
+// NewLogSink creates a logr.LogSink backed by MultiLogger
+func NewLogSink() *LogSink {
+	return &LogSink{
+		fields: logrus.Fields{},
+	}
+}
+
+// NewLogr creates a logr.Logger backed by MultiLogger
+func NewLogr() logr.Logger {
+	return logr.New(NewLogSink())
+}
+
+// verboseLevel maps V-level to log level, like the Verbose option
+func verboseLevel(level int) logrus.Level {
+	switch level {
+	case 0:
+		return logrus.InfoLevel
+	case 1:
+		return logrus.DebugLevel
+	default:
+		return logrus.TraceLevel
+	}
+}
+
+// Init receives runtime info from logr, which is not used
+func (s *LogSink) Init(info logr.RuntimeInfo) {
+}
+
+// Enabled checks if console or file will log entries of V-level
+func (s *LogSink) Enabled(level int) bool {
+	return mLogger.IsLevelEnabled(verboseLevel(level))
+}
+
+// Info logs a non-error message with key/value pairs
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.logger(keysAndValues).Log(verboseLevel(level), msg)
+}
+
+// Error logs an error with key/value pairs, and err as "error" field
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	logger := s.logger(keysAndValues)
+	logger.Fields[errorKey] = err
+	logger.Log(logrus.ErrorLevel, msg)
+}
+
+// WithValues returns a new LogSink with additional key/value pairs
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	s2 := s.clone()
+	addKeysAndValues(s2.fields, keysAndValues)
+	return s2
+}
+
+// WithName returns a new LogSink with name appended to "logger" field
+func (s *LogSink) WithName(name string) logr.LogSink {
+	s2 := s.clone()
+	if s2.name == "" {
+		s2.name = name
+	} else {
+		s2.name += "." + name
+	}
+	return s2
+}
+
+func (s *LogSink) clone() *LogSink {
+	fields := make(logrus.Fields, len(s.fields))
+	for k, v := range s.fields {
+		fields[k] = v
+	}
+	return &LogSink{
+		name:   s.name,
+		fields: fields,
+	}
+}
+
+func (s *LogSink) logger(keysAndValues []interface{}) *MultiLoggerWithFields {
+	fields := make(logrus.Fields, len(s.fields)+len(keysAndValues)/2+1)
+	for k, v := range s.fields {
+		fields[k] = v
+	}
+	addKeysAndValues(fields, keysAndValues)
+	if s.name != "" {
+		fields["logger"] = s.name
+	}
+	return newLoggerWithFields(mLogger, fields)
+}
+
+func addKeysAndValues(fields logrus.Fields, keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		if i+1 < len(keysAndValues) {
+			fields[key] = keysAndValues[i+1]
+		} else {
+			fields[key] = "(MISSING)"
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogSink(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		Verbose: 2,
+		stderr:  &buffer,
+	})
+	defer Init(Options{})
+
+	logger := NewLogr().WithName("controller").WithName("pod").WithValues("ns", "default")
+	logger.Info("info #1", "name", "demo")
+	logger.V(1).Info("debug #2")
+	logger.V(2).Info("trace is filtered")
+	logger.Error(errors.New("failure"), "error #3", "odd")
+
+	assert.True(logger.V(1).Enabled())
+	assert.False(logger.V(2).Enabled())
+
+	expect = `INFO: info #1                                      (logger=controller.pod name=demo ns=default)
+DEBUG: debug #2                                     (logger=controller.pod ns=default)
+ERROR: error #3                                     (error=failure logger=controller.pod ns=default odd=(MISSING))
+`
+	assert.Equal(expect, buffer.String())
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiLineIndent(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		MultiLine: formatter.MultiLineIndent,
+		stderr:    &buffer,
+	})
+	defer Init(Options{})
+
+	Errorf("command failed:\n%s", "line 1\nline 2\n")
+	WithField("code", 1).Warn("exit\nstatus")
+	Notef("note 1\nnote 2")
+
+	expect = `ERROR: command failed:
+       line 1
+       line 2
+WARNING: exit
+         status                                       (code=1)
+NOTE: note 1
+      note 2
+`
+	assert.Equal(expect, buffer.String())
+}
+
+func TestMultiLinePrefix(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		MultiLine: formatter.MultiLinePrefix,
+		stderr:    &buffer,
+	})
+	defer Init(Options{})
+
+	Error("line 1\nline 2")
+	assert.Equal("WARN: warn 1\nWARN: warn 2\n", Swarn("warn 1\nwarn 2\n"))
+
+	expect = `ERROR: line 1
+ERROR: line 2
+`
+	assert.Equal(expect, buffer.String())
+}
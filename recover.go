@@ -0,0 +1,61 @@
+package log
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RecoverAction defines what to do after a recovered panic is logged
+type RecoverAction int
+
+const (
+	// RecoverContinue stops the panic, and the goroutine continues
+	RecoverContinue RecoverAction = iota
+	// RecoverRepanic panics again with the recovered value
+	RecoverRepanic
+	// RecoverExit calls the exit function
+	RecoverExit
+)
+
+const recoverExitCode = 2
+
+// RecoverAndLog recovers panic, and logs the panic value and stack trace
+// with fields at PanicLevel to all outputs. It must be called by defer:
+//
+//	defer log.RecoverAndLog(nil)
+func RecoverAndLog(fields map[string]interface{}) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	logPanic(fields, fmt.Sprintf("panic: %v\n%s", r, debug.Stack()))
+	Flush()
+
+	switch o.RecoverAction {
+	case RecoverRepanic:
+		panic(r)
+	case RecoverExit:
+		callExitFunc(recoverExitCode)
+	}
+}
+
+// Go runs fn in a new goroutine, and panic of fn is logged by
+// RecoverAndLog
+func Go(fn func()) {
+	go func() {
+		defer RecoverAndLog(nil)
+		fn()
+	}()
+}
+
+// logPanic logs msg at PanicLevel to all loggers. The entry is written to
+// all loggers before logrus panics, and the panic is recovered.
+func logPanic(fields map[string]interface{}, msg string) {
+	defer func() {
+		recover()
+	}()
+	mLogger.WithFields(fields).Log(logrus.PanicLevel, msg)
+}
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverAndLog(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile: tmpLog,
+		stderr:  &buffer,
+	})
+	defer Init(Options{})
+
+	func() {
+		defer RecoverAndLog(map[string]interface{}{
+			"task": "demo",
+			"id": LazyValue(func() interface{} {
+				return 1
+			}),
+		})
+		panic("boom")
+	}()
+
+	assert.True(strings.HasPrefix(buffer.String(), "PANIC: panic: boom\ngoroutine "))
+	assert.Contains(buffer.String(), "TestRecoverAndLog")
+	assert.Contains(buffer.String(), "(id=1 task=demo)\n")
+
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(filterTime(string(data)), "PANI[<time>]: panic: boom\ngoroutine "))
+	assert.Contains(string(data), "(id=1 task=demo)\n")
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	Init(Options{
+		RecoverAction: RecoverRepanic,
+		stderr:        &buffer,
+	})
+	defer Init(Options{})
+
+	assert.PanicsWithValue("boom", func() {
+		defer RecoverAndLog(nil)
+		panic("boom")
+	})
+	assert.True(strings.HasPrefix(buffer.String(), "PANIC: panic: boom\n"))
+}
+
+func TestGoRecoverExit(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		code   = make(chan int, 1)
+	)
+
+	Init(Options{
+		RecoverAction: RecoverExit,
+		stderr:        &buffer,
+		exitFunc: func(c int) {
+			code <- c
+		},
+	})
+	defer Init(Options{})
+
+	Go(func() {
+		panic("boom in goroutine")
+	})
+
+	assert.Equal(2, <-code)
+	assert.True(strings.HasPrefix(buffer.String(), "PANIC: panic: boom in goroutine\n"))
+}
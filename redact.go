@@ -0,0 +1,203 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRedactReplacement = "[REDACTED]"
+	redactMask               = "****"
+)
+
+// Common patterns of secrets for RedactRules.Values
+var (
+	RedactBearerToken  = regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9._~+/=-]+`)
+	RedactAWSAccessKey = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+	RedactEmail        = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+)
+
+// RedactRules defines which fields and values are redacted before entries
+// are formatted.
+type RedactRules struct {
+	// Keys are field keys whose values are redacted. Keys are matched
+	// case-insensitively, and may be glob patterns, such as "*token*".
+	Keys []string
+	// Values are patterns of secrets. Matched text in messages and values
+	// of all fields is redacted. Non-string values are checked as formatted
+	// text, and errors are checked with all errors they wrap.
+	Values []*regexp.Regexp
+	// Allow are field keys (or glob patterns) which are never redacted
+	Allow []string
+	// Replacement replaces redacted text, default is "[REDACTED]"
+	Replacement string
+	// KeepSuffix keeps the last KeepSuffix characters of redacted text,
+	// such as "****abcd", if the text is at least twice as long
+	KeepSuffix int
+}
+
+// redactHook redacts fields and message of entries
+type redactHook struct {
+	rules RedactRules
+}
+
+func newRedactHook(rules RedactRules) *redactHook {
+	if rules.Replacement == "" {
+		rules.Replacement = defaultRedactReplacement
+	}
+	rules.Keys = lowerStrings(rules.Keys)
+	rules.Allow = lowerStrings(rules.Allow)
+	return &redactHook{rules: rules}
+}
+
+func lowerStrings(list []string) []string {
+	lower := make([]string, len(list))
+	for i, s := range list {
+		lower[i] = strings.ToLower(s)
+	}
+	return lower
+}
+
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.redactValue(entry.Message)
+	for k, v := range entry.Data {
+		if matchKey(h.rules.Allow, k) {
+			continue
+		}
+		if matchKey(h.rules.Keys, k) {
+			s := ""
+			if v != nil {
+				s = fmt.Sprint(v)
+			}
+			entry.Data[k] = h.mask(s)
+			continue
+		}
+		if len(h.rules.Values) == 0 {
+			continue
+		}
+		// Lazy values must be evaluated to be checked
+		if value, ok := formatter.ResolveValue(v); ok {
+			v = value
+			entry.Data[k] = v
+		}
+		switch value := v.(type) {
+		case nil:
+		case string:
+			entry.Data[k] = h.redactValue(value)
+		case []byte:
+			s := string(value)
+			if redacted := h.redactValue(s); redacted != s {
+				entry.Data[k] = redacted
+			}
+		case error:
+			if err, ok := h.redactError(value); ok {
+				entry.Data[k] = err
+			}
+		default:
+			// Values, such as fmt.Stringer, are checked as formatted text,
+			// and keep their types if nothing is redacted
+			s := fmt.Sprint(value)
+			if redacted := h.redactValue(s); redacted != s {
+				entry.Data[k] = redacted
+			}
+		}
+	}
+	return nil
+}
+
+// redactedError is a copy of an error and errors it wraps with redacted
+// messages. Stack traces of the original errors are kept.
+type redactedError struct {
+	msg    string
+	causes []error
+	stack  []uintptr
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() []error {
+	return e.causes
+}
+
+// StackTrace returns program counters of the stack of the original error
+func (e *redactedError) StackTrace() []uintptr {
+	return e.stack
+}
+
+// redactError redacts messages of err and errors it wraps, and returns
+// false if nothing is redacted
+func (h *redactHook) redactError(err error) (error, bool) {
+	var (
+		msg      = err.Error()
+		redacted = &redactedError{
+			msg:   h.redactValue(msg),
+			stack: formatter.StackTrace(err),
+		}
+		changed = redacted.msg != msg
+		causes  []error
+	)
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		causes = []error{e.Unwrap()}
+	case interface{ Unwrap() []error }:
+		causes = e.Unwrap()
+	}
+	for _, cause := range causes {
+		if cause == nil {
+			continue
+		}
+		if r, ok := h.redactError(cause); ok {
+			cause = r
+			changed = true
+		}
+		redacted.causes = append(redacted.causes, cause)
+	}
+	if !changed {
+		return err, false
+	}
+	return redacted, true
+}
+
+// redactValue redacts text matched with patterns of values
+func (h *redactHook) redactValue(s string) string {
+	for _, re := range h.rules.Values {
+		s = re.ReplaceAllStringFunc(s, h.mask)
+	}
+	return s
+}
+
+// mask returns replacement of s, which keeps the last characters of s if
+// configured
+func (h *redactHook) mask(s string) string {
+	n := h.rules.KeepSuffix
+	if runes := []rune(s); n > 0 && len(runes) >= 2*n {
+		return redactMask + string(runes[len(runes)-n:])
+	}
+	return h.rules.Replacement
+}
+
+// matchKey checks if key matches any of lower case patterns
+func matchKey(patterns []string, key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range patterns {
+		if pattern == key {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jiangxin/multi-log/formatter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeModeEscape(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile:  tmpLog,
+		SafeMode: formatter.SafeModeEscape,
+		stderr:   &buffer,
+	})
+	defer Init(Options{})
+
+	WithField("user", "bob\nERRO[0000]: forged").
+		Warnf("login %s\n", "alice\r\nERRO[0000]: \x1b[1mforged")
+
+	expect = `WARNING: login alice\r\nERRO[0000]: \x1b[1mforged     (user=bob\nERRO[0000]: forged)
+`
+	assert.Equal(expect, buffer.String())
+
+	expect = `WARN[<time>]: login alice\r\nERRO[0000]: \x1b[1mforged     (user=bob\nERRO[0000]: forged)
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+}
+
+func TestSafeModeEscapeWithError(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		SafeMode: formatter.SafeModeEscape,
+		stderr:   &buffer,
+	})
+	defer Init(Options{})
+
+	err := &wrapError{msg: "open", err: errors.New("denied\nERROR: forged")}
+	WithError(err).Error("failed")
+
+	expect = `ERROR: failed                                       (error=open: denied\nERROR: forged)\n    caused by: denied\nERROR: forged
+`
+	assert.Equal(expect, buffer.String())
+}
+
+func TestSafeModeIndent(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		SafeMode: formatter.SafeModeIndent,
+		stderr:   &buffer,
+	})
+	defer Init(Options{})
+
+	Warn("line 1\nERROR: forged\r\n")
+
+	expect = `WARNING: line 1
+    | ERROR: forged\r
+`
+	assert.Equal(expect, buffer.String())
+	assert.Equal("tab\tok", formatter.Sanitize("tab\tok", formatter.SafeModeEscape))
+	assert.Equal(`a\u2028b`, formatter.Sanitize("a\u2028b", formatter.SafeModeEscape))
+}
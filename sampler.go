@@ -0,0 +1,156 @@
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSamplingInterval = time.Second
+	maxSamplerKeys          = 4096
+)
+
+// SamplingRule defines how to sample messages of one level. In each
+// interval, the First messages with the same template are logged, and
+// then every Thereafter-th message. If Thereafter is 0, all messages
+// after the First are suppressed.
+type SamplingRule struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+type samplerKey struct {
+	level logrus.Level
+	key   string
+}
+
+type samplerCounter struct {
+	start      time.Time
+	count      int
+	suppressed int
+	timer      *time.Timer
+}
+
+// sampler limits messages with the same template (format string of Logf,
+// or message of Log and Logln), and reports number of suppressed messages
+// at the end of each interval.
+type sampler struct {
+	mu       sync.Mutex
+	rules    map[logrus.Level]SamplingRule
+	counters map[samplerKey]*samplerCounter
+	report   func(level logrus.Level, format string, args ...interface{})
+}
+
+var (
+	mSampler *sampler
+)
+
+func newSampler(rules map[logrus.Level]SamplingRule) *sampler {
+	s := &sampler{
+		rules:    make(map[logrus.Level]SamplingRule),
+		counters: make(map[samplerKey]*samplerCounter),
+		report:   mLogger.logf,
+	}
+	for level, rule := range rules {
+		if rule.Interval <= 0 {
+			rule.Interval = defaultSamplingInterval
+		}
+		s.rules[level] = rule
+	}
+	return s
+}
+
+// allow checks whether message with the template should be logged.
+// Fatal and panic messages are never suppressed.
+func (s *sampler) allow(level logrus.Level, key string) bool {
+	if s == nil || level <= logrus.FatalLevel {
+		return true
+	}
+	rule, ok := s.rules[level]
+	if !ok {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	k := samplerKey{level: level, key: key}
+	c := s.counters[k]
+	if c == nil {
+		if len(s.counters) >= maxSamplerKeys {
+			s.prune(now)
+		}
+		c = &samplerCounter{start: now}
+		s.counters[k] = c
+	} else if now.Sub(c.start) >= rule.Interval {
+		c.start = now
+		c.count = 0
+	}
+
+	c.count++
+	if c.count <= rule.First {
+		return true
+	}
+	if rule.Thereafter > 0 && (c.count-rule.First)%rule.Thereafter == 0 {
+		return true
+	}
+
+	c.suppressed++
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.start.Add(rule.Interval).Sub(now), func() {
+			s.flush(k)
+		})
+	}
+	return false
+}
+
+// flush reports suppressed messages of the key
+func (s *sampler) flush(k samplerKey) {
+	s.mu.Lock()
+	c := s.counters[k]
+	if c == nil {
+		s.mu.Unlock()
+		return
+	}
+	n := c.suppressed
+	c.suppressed = 0
+	c.timer = nil
+	s.mu.Unlock()
+
+	if n > 0 {
+		s.report(k.level, "suppressed %d similar messages: %s", n, k.key)
+	}
+}
+
+// prune removes counters without pending reports
+func (s *sampler) prune(now time.Time) {
+	for k, c := range s.counters {
+		if c.timer == nil && now.Sub(c.start) >= s.rules[k.level].Interval {
+			delete(s.counters, k)
+		}
+	}
+}
+
+// stop reports all suppressed messages, and stops timers
+func (s *sampler) stop() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	keys := []samplerKey{}
+	for k, c := range s.counters {
+		if c.timer != nil && c.timer.Stop() {
+			keys = append(keys, k)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, k := range keys {
+		s.flush(k)
+	}
+}
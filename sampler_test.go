@@ -0,0 +1,91 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampler(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		Sampling: map[logrus.Level]SamplingRule{
+			logrus.WarnLevel: {
+				Interval:   time.Hour,
+				First:      2,
+				Thereafter: 3,
+			},
+		},
+		stderr: &buffer,
+	})
+
+	for i := 1; i <= 10; i++ {
+		Warnf("warn #%d", i)
+		WithField("i", strconv.Itoa(i)).Error("error")
+		Info("info is not enabled")
+	}
+
+	// Report suppressed messages when stopped
+	Init(Options{})
+
+	expect = `WARNING: warn #1
+ERROR: error                                        (i=1)
+WARNING: warn #2
+ERROR: error                                        (i=2)
+ERROR: error                                        (i=3)
+ERROR: error                                        (i=4)
+WARNING: warn #5
+ERROR: error                                        (i=5)
+ERROR: error                                        (i=6)
+ERROR: error                                        (i=7)
+WARNING: warn #8
+ERROR: error                                        (i=8)
+ERROR: error                                        (i=9)
+ERROR: error                                        (i=10)
+WARNING: suppressed 6 similar messages: warn #%d
+`
+	assert.Equal(expect, buffer.String())
+}
+
+func TestSamplerInterval(t *testing.T) {
+	var (
+		assert   = assert.New(t)
+		reported []string
+	)
+
+	s := newSampler(map[logrus.Level]SamplingRule{
+		logrus.InfoLevel: {
+			Interval: 50 * time.Millisecond,
+			First:    1,
+		},
+	})
+	done := make(chan struct{})
+	s.report = func(level logrus.Level, format string, args ...interface{}) {
+		reported = append(reported, fmt.Sprintf(format, args...))
+		close(done)
+	}
+
+	assert.True(s.allow(logrus.InfoLevel, "info"))
+	assert.False(s.allow(logrus.InfoLevel, "info"))
+	assert.False(s.allow(logrus.InfoLevel, "info"))
+	assert.True(s.allow(logrus.DebugLevel, "debug"))
+	assert.True(s.allow(logrus.FatalLevel, "fatal"))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("suppressed messages are not reported")
+	}
+	assert.Equal([]string{"suppressed 2 similar messages: info"}, reported)
+	assert.True(s.allow(logrus.InfoLevel, "info"))
+}
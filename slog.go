@@ -0,0 +1,121 @@
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SlogHandler is a slog.Handler which sends records through MultiLogger,
+// so records are shown on console and saved in log file according to
+// Verbose and LogLevel.
+type SlogHandler struct {
+	fields logrus.Fields
+	prefix string
+}
+
+// NewSlogHandler creates a slog.Handler backed by MultiLogger
+func NewSlogHandler() *SlogHandler {
+	return &SlogHandler{
+		fields: logrus.Fields{},
+	}
+}
+
+// slogLevel maps slog level to logrus level. Levels above error are
+// mapped to error, so slog never exits or panics.
+func slogLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level >= slog.LevelDebug:
+		return logrus.DebugLevel
+	default:
+		return logrus.TraceLevel
+	}
+}
+
+// Enabled checks if console or file will log records of level
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return mLogger.IsLevelEnabled(slogLevel(level))
+}
+
+// Handle sends record to MultiLogger
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := contextFields(ctx)
+	fields[entryTimeKey] = r.Time
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+
+	newLoggerWithFields(mLogger, fields).Log(slogLevel(r.Level), r.Message)
+	return nil
+}
+
+// WithAttrs returns a handler with attrs as fields
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := h.clone()
+	for _, a := range attrs {
+		addSlogAttr(h2.fields, h2.prefix, a)
+	}
+	return h2
+}
+
+// WithGroup returns a handler which uses name as prefix of fields
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.prefix += name + "."
+	return h2
+}
+
+func (h *SlogHandler) clone() *SlogHandler {
+	fields := make(logrus.Fields, len(h.fields))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	return &SlogHandler{
+		fields: fields,
+		prefix: h.prefix,
+	}
+}
+
+// addSlogAttr adds attr into fields, and attrs of group are flattened
+// using dotted keys
+func addSlogAttr(fields logrus.Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range attrs {
+			addSlogAttr(fields, prefix, ga)
+		}
+		return
+	}
+
+	fields[prefix+a.Key] = a.Value.Any()
+}
@@ -0,0 +1,147 @@
+// +build go1.22
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var (
+		logFile string
+		count   int
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+	defer Init(Options{})
+
+	// Pattern of entry in log file, such as "INFO[<time>]: msg (a=b G.c=d)"
+	pattern := regexp.MustCompile(`^([A-Z]+)(?:\[([^\]]*)\])?: (.*?) *(?:\((.*)\))?$`)
+
+	slogtest.Run(t, func(t *testing.T) slog.Handler {
+		count++
+		logFile = filepath.Join(tmpdir, fmt.Sprintf("log-%d.txt", count))
+		Init(Options{
+			LogFile:  logFile,
+			LogLevel: "trace",
+			stderr:   ioutil.Discard,
+		})
+		return NewSlogHandler()
+	}, func(t *testing.T) map[string]interface{} {
+		data, err := ioutil.ReadFile(logFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matches := pattern.FindStringSubmatch(strings.TrimSuffix(string(data), "\n"))
+		if matches == nil {
+			t.Fatalf("bad entry: %s", data)
+		}
+
+		result := map[string]interface{}{
+			slog.LevelKey:   matches[1],
+			slog.MessageKey: matches[3],
+		}
+		if matches[2] != "" {
+			result[slog.TimeKey] = matches[2]
+		}
+		if matches[4] == "" {
+			return result
+		}
+
+		// Attrs of groups are saved using dotted keys
+		for _, field := range strings.Split(matches[4], " ") {
+			kv := strings.SplitN(field, "=", 2)
+			keys := strings.Split(kv[0], ".")
+			group := result
+			for _, key := range keys[:len(keys)-1] {
+				sub, ok := group[key].(map[string]interface{})
+				if !ok {
+					sub = map[string]interface{}{}
+					group[key] = sub
+				}
+				group = sub
+			}
+			group[keys[len(keys)-1]] = kv[1]
+		}
+		return result
+	})
+}
+
+func TestSlogHandlerTime(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile: tmpLog,
+		stderr:  &buffer,
+	})
+	defer Init(Options{})
+
+	h := NewSlogHandler()
+	r := slog.NewRecord(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelWarn, "warn", 0)
+	assert.Nil(h.Handle(context.Background(), r))
+	r = slog.NewRecord(time.Time{}, slog.LevelError, "error", 0)
+	assert.Nil(h.Handle(context.Background(), r))
+
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal("WARN[2019-01-02T03:04:05Z]: warn\nERRO: error\n", string(data))
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		Verbose: 1,
+		stderr:  &buffer,
+	})
+	defer Init(Options{})
+
+	logger := slog.New(NewSlogHandler()).WithGroup("req").With("id", "1234")
+	logger.Debug("debug is filtered")
+	logger.Info("info")
+	logger.Warn("warn", slog.Group("user", "name", "jiangxin"))
+	logger.Log(context.Background(), slog.LevelError+4, "error")
+
+	assert.False(logger.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(logger.Enabled(context.Background(), slog.LevelInfo))
+
+	expect = `INFO: info                                         (req.id=1234)
+WARNING: warn                                         (req.id=1234 req.user.name=jiangxin)
+ERROR: error                                        (req.id=1234)
+`
+	assert.Equal(expect, buffer.String())
+}
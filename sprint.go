@@ -79,10 +79,8 @@ func sprint(prefix string, args ...interface{}) string {
 		)
 	}
 
-	msg += fmt.Sprint(args...)
-	if !strings.HasSuffix(msg, "\n") {
-		msg += "\n"
-	}
+	body := strings.TrimSuffix(fmt.Sprint(args...), "\n")
+	msg += f.FormatMultiLine(msg, "", body) + "\n"
 	return msg
 }
 
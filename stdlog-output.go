@@ -0,0 +1,13 @@
+// +build go1.13
+
+package log
+
+import (
+	"io"
+	stdlog "log"
+)
+
+// stdlogOutput returns output of the standard logger
+func stdlogOutput() io.Writer {
+	return stdlog.Writer()
+}
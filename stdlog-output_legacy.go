@@ -0,0 +1,14 @@
+// +build !go1.13
+
+package log
+
+import (
+	"io"
+	"os"
+)
+
+// stdlogOutput returns os.Stderr, which is the default output of the
+// standard logger, because log.Writer is not available before go 1.13
+func stdlogOutput() io.Writer {
+	return os.Stderr
+}
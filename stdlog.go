@@ -0,0 +1,134 @@
+package log
+
+import (
+	stdlog "log"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lmsgprefix is log.Lmsgprefix, which is defined since go 1.14
+const lmsgprefix = 1 << 6
+
+// stdlogWriter parses lines written by a logger of the standard library
+// log package, and sends the messages to MultiLogger. Flags and prefix of
+// the logger are saved when it is redirected, because they cannot be read
+// while the logger is writing.
+type stdlogWriter struct {
+	header      *regexp.Regexp
+	level       logrus.Level
+	detectLevel bool
+}
+
+func newStdlogWriter(flags int, prefix string, level logrus.Level, detectLevel bool) *stdlogWriter {
+	return &stdlogWriter{
+		header:      stdlogHeader(flags, prefix),
+		level:       level,
+		detectLevel: detectLevel,
+	}
+}
+
+// stdlogHeader returns an anchored pattern of prefix, date, time and file
+// written by a logger with flags. Each part is optional, so a message is
+// never cut if flags are changed after the logger is redirected.
+func stdlogHeader(flags int, prefix string) *regexp.Regexp {
+	var pattern strings.Builder
+
+	pattern.WriteString("^")
+	if prefix != "" && flags&lmsgprefix == 0 {
+		pattern.WriteString("(?:" + regexp.QuoteMeta(prefix) + ")?")
+	}
+	if flags&stdlog.Ldate != 0 {
+		// 2009/01/23
+		pattern.WriteString(`(?:\d{4}/\d{2}/\d{2} )?`)
+	}
+	if flags&(stdlog.Ltime|stdlog.Lmicroseconds) != 0 {
+		// 01:23:23 or 01:23:23.123123
+		if flags&stdlog.Lmicroseconds != 0 {
+			pattern.WriteString(`(?:\d{2}:\d{2}:\d{2}\.\d{6} )?`)
+		} else {
+			pattern.WriteString(`(?:\d{2}:\d{2}:\d{2} )?`)
+		}
+	}
+	if flags&(stdlog.Lshortfile|stdlog.Llongfile) != 0 {
+		// file.go:23
+		pattern.WriteString(`(?:\S+?:\d+: )?`)
+	}
+	if prefix != "" && flags&lmsgprefix != 0 {
+		pattern.WriteString("(?:" + regexp.QuoteMeta(prefix) + ")?")
+	}
+	return regexp.MustCompile(pattern.String())
+}
+
+// RedirectStdlog redirects the standard logger of the log package into
+// MultiLogger. Messages are logged at level, or at the level detected from
+// prefixes like "[WARN]" if detectLevel is true. Call restore to write to
+// the original output again, which is os.Stderr before go 1.13. Redirect
+// again after flags or prefix of the standard logger are changed.
+func RedirectStdlog(level logrus.Level, detectLevel bool) (restore func()) {
+	out := stdlogOutput()
+	stdlog.SetOutput(newStdlogWriter(stdlog.Flags(), stdlog.Prefix(), level, detectLevel))
+	return func() {
+		stdlog.SetOutput(out)
+	}
+}
+
+// RedirectStdlogger redirects logger of the log package into MultiLogger
+func RedirectStdlogger(logger *stdlog.Logger, level logrus.Level, detectLevel bool) (restore func()) {
+	out := logger.Writer()
+	logger.SetOutput(newStdlogWriter(logger.Flags(), logger.Prefix(), level, detectLevel))
+	return func() {
+		logger.SetOutput(out)
+	}
+}
+
+func (w *stdlogWriter) Write(p []byte) (int, error) {
+	var (
+		msg   = strings.TrimSuffix(string(p), "\n")
+		level = w.level
+	)
+
+	if loc := w.header.FindStringIndex(msg); loc != nil {
+		msg = msg[loc[1]:]
+	}
+
+	if w.detectLevel {
+		level, msg = detectLevel(msg, level)
+	}
+	mLogger.Log(level, msg)
+	return len(p), nil
+}
+
+// detectLevel parses level from prefixes like "[WARN]" or "ERROR:". Panic
+// is logged at fatal level, because the log package panics itself.
+func detectLevel(msg string, level logrus.Level) (logrus.Level, string) {
+	var name, rest string
+
+	if strings.HasPrefix(msg, "[") {
+		i := strings.Index(msg, "]")
+		if i < 0 {
+			return level, msg
+		}
+		name, rest = msg[1:i], msg[i+1:]
+	} else {
+		i := strings.Index(msg, ":")
+		if i < 0 {
+			return level, msg
+		}
+		name, rest = msg[:i], msg[i+1:]
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "err" {
+		name = "error"
+	}
+	l, err := logrus.ParseLevel(name)
+	if err != nil {
+		return level, msg
+	}
+	if l == logrus.PanicLevel {
+		l = logrus.FatalLevel
+	}
+	return l, strings.TrimLeft(rest, " ")
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"bytes"
+	stdlog "log"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectStdlog(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		Verbose: 1,
+		stderr:  &buffer,
+	})
+	defer Init(Options{})
+
+	flags := stdlog.Flags()
+	prefix := stdlog.Prefix()
+	defer func() {
+		stdlog.SetFlags(flags)
+		stdlog.SetPrefix(prefix)
+	}()
+
+	stdlog.SetFlags(stdlog.LstdFlags | stdlog.Lmicroseconds | stdlog.Lshortfile)
+	stdlog.SetPrefix("lib: ")
+	restore := RedirectStdlog(logrus.InfoLevel, true)
+	stdlog.Print("info #1")
+	stdlog.Printf("[WARN] warn #%d", 2)
+	stdlog.Println("ERROR: error #3")
+	stdlog.Print("[DEBUG] debug #4")
+	restore()
+
+	stdlog.SetFlags(stdlog.Ldate | lmsgprefix)
+	restore = RedirectStdlog(logrus.InfoLevel, true)
+	stdlog.Print("[err] error #5")
+	stdlog.Print("[unknown] info #6")
+	restore()
+
+	// Flags are changed without redirecting again
+	restore = RedirectStdlog(logrus.InfoLevel, true)
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.Print("connection established to server")
+	restore()
+
+	logger := stdlog.New(&bytes.Buffer{}, "", stdlog.Ltime)
+	defer RedirectStdlogger(logger, logrus.WarnLevel, false)()
+	logger.Print("[ERROR] warn #7")
+
+	expect = `INFO: info #1
+WARNING: warn #2
+ERROR: error #3
+ERROR: error #5
+INFO: [unknown] info #6
+INFO: connection established to server
+WARNING: [ERROR] warn #7
+`
+	assert.Equal(expect, buffer.String())
+}
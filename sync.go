@@ -0,0 +1,130 @@
+package log
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SyncOptions defines when to fsync log file. Log file is synced if any
+// condition is met, and never synced if all are zero.
+type SyncOptions struct {
+	// Entries syncs after every Entries entries
+	Entries int
+	// Interval syncs at most Interval after an entry is written
+	Interval time.Duration
+	// OnError syncs after each entry of ErrorLevel or above
+	OnError bool
+}
+
+// syncer is implemented by outputs which can be synced, such as os.File
+type syncer interface {
+	Sync() error
+}
+
+// levelWriter is implemented by outputs which need level of entries
+type levelWriter interface {
+	WriteLevel(level logrus.Level, p []byte) error
+}
+
+// SyncWriter syncs the output according to SyncOptions. It also takes
+// over the formatter of the logger, which tells SyncWriter level of the
+// entry being written. Logrus formats and writes an entry with the lock of
+// logger held, so the level always belongs to the entry being written.
+type SyncWriter struct {
+	mu        sync.Mutex
+	out       io.Writer
+	formatter logrus.Formatter
+	options   SyncOptions
+	level     logrus.Level
+	count     int
+	timer     *time.Timer
+}
+
+// NewSyncWriter creates SyncWriter for out, and wraps the formatter of
+// logger if logger is not nil
+func NewSyncWriter(out io.Writer, options SyncOptions, logger *logrus.Logger) *SyncWriter {
+	w := &SyncWriter{
+		out:     out,
+		options: options,
+		level:   logrus.InfoLevel,
+	}
+	if logger != nil {
+		w.formatter = logger.Formatter
+		logger.Formatter = w
+	}
+	return w
+}
+
+// Format formats entry using the original formatter, and saves level of
+// entry, which is written right after it is formatted
+func (w *SyncWriter) Format(entry *logrus.Entry) ([]byte, error) {
+	serialized, err := w.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	w.level = entry.Level
+	w.mu.Unlock()
+	return serialized, nil
+}
+
+// Write writes p with level of the last formatted entry
+func (w *SyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	level := w.level
+	w.mu.Unlock()
+
+	if err := w.WriteLevel(level, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteLevel writes p, and syncs if needed
+func (w *SyncWriter) WriteLevel(level logrus.Level, p []byte) error {
+	if _, err := w.out.Write(p); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.count++
+	if w.options.OnError && level <= logrus.ErrorLevel ||
+		w.options.Entries > 0 && w.count >= w.options.Entries {
+		return w.sync()
+	}
+	if w.options.Interval > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.options.Interval, func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			w.timer = nil
+			if w.count > 0 {
+				w.sync()
+			}
+		})
+	}
+	return nil
+}
+
+// Sync syncs the output
+func (w *SyncWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sync()
+}
+
+func (w *SyncWriter) sync() error {
+	w.count = 0
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if s, ok := w.out.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLogfile struct {
+	bytes.Buffer
+	mu    sync.Mutex
+	syncs int
+}
+
+func (f *fakeLogfile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.syncs++
+	return nil
+}
+
+func (f *fakeLogfile) Close() error {
+	return nil
+}
+
+func (f *fakeLogfile) Syncs() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.syncs
+}
+
+// initFakeLogfile initializes log with a fake log file, and returns the
+// fake log file and a function to restore
+func initFakeLogfile(options Options) (*fakeLogfile, func()) {
+	file := &fakeLogfile{}
+	saved := openFile
+	openFile = func(name string, flag int, perm os.FileMode) (logfile, error) {
+		return file, nil
+	}
+
+	tmpdir, _ := ioutil.TempDir("", "multi-logger-")
+	options.LogFile = filepath.Join(tmpdir, "log.txt")
+	options.LogLevel = "info"
+	options.stderr = ioutil.Discard
+	Init(options)
+
+	return file, func() {
+		Init(Options{})
+		openFile = saved
+		os.RemoveAll(tmpdir)
+	}
+}
+
+func TestSyncNever(t *testing.T) {
+	assert := assert.New(t)
+	file, restore := initFakeLogfile(Options{})
+	defer restore()
+
+	Info("info #1")
+	Error("error #2")
+	assert.Equal(0, file.Syncs())
+	assert.Equal("INFO[<time>]: info #1\nERRO[<time>]: error #2\n", filterTime(file.String()))
+}
+
+func TestSyncEveryEntries(t *testing.T) {
+	assert := assert.New(t)
+	file, restore := initFakeLogfile(Options{
+		Sync: SyncOptions{Entries: 2},
+	})
+	defer restore()
+
+	for i := 0; i < 5; i++ {
+		Info("info")
+	}
+	assert.Equal(2, file.Syncs())
+}
+
+func TestSyncOnError(t *testing.T) {
+	assert := assert.New(t)
+	file, restore := initFakeLogfile(Options{
+		Sync: SyncOptions{OnError: true},
+	})
+	defer restore()
+
+	Info("info #1")
+	Warn("warn #2")
+	assert.Equal(0, file.Syncs())
+	Error("error #3")
+	assert.Equal(1, file.Syncs())
+	WithField("code", 1).Errorf("error #%d", 4)
+	assert.Equal(2, file.Syncs())
+}
+
+func TestSyncOnErrorConcurrent(t *testing.T) {
+	assert := assert.New(t)
+	file, restore := initFakeLogfile(Options{
+		Sync: SyncOptions{OnError: true},
+	})
+	defer restore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if i%2 == 0 {
+					Error("error")
+				} else {
+					Info("info")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(4000, file.Syncs())
+}
+
+func TestSyncOnErrorAsync(t *testing.T) {
+	assert := assert.New(t)
+	file, restore := initFakeLogfile(Options{
+		Sync:  SyncOptions{OnError: true},
+		Async: true,
+	})
+	defer restore()
+
+	Info("info #1")
+	Error("error #2")
+	Info("info #3")
+	Flush()
+	assert.Equal(1, file.Syncs())
+}
+
+func TestSyncInterval(t *testing.T) {
+	assert := assert.New(t)
+	file, restore := initFakeLogfile(Options{
+		Sync: SyncOptions{Interval: 20 * time.Millisecond},
+	})
+	defer restore()
+
+	Info("info #1")
+	Info("info #2")
+	assert.Equal(0, file.Syncs())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(1, file.Syncs())
+}
@@ -1,6 +1,9 @@
 package log
 
 import (
+	"fmt"
+
+	"github.com/jiangxin/multi-log/formatter"
 	"github.com/sirupsen/logrus"
 )
 
@@ -13,8 +16,12 @@ type MultiLoggerWithFields struct {
 
 // WithFields writes log with fields
 func WithFields(fields map[string]interface{}) *MultiLoggerWithFields {
+	return mLogger.WithFields(fields)
+}
+
+func newLoggerWithFields(base MultiLogger, fields logrus.Fields) *MultiLoggerWithFields {
 	logger := new(MultiLoggerWithFields)
-	logger.MultiLogger = mLogger
+	logger.MultiLogger = base
 	logger.Fields = fields
 	logger.MultiLogger.self = logger
 	return logger
@@ -25,35 +32,97 @@ func WithField(key string, value interface{}) *MultiLoggerWithFields {
 	return WithFields(logrus.Fields{key: value})
 }
 
-// Log defines core log methods for MultiLoggerWithFields
-func (v *MultiLoggerWithFields) Log(level logrus.Level, args ...interface{}) {
-	if v.StdLogger != nil {
-		v.StdLogger.WithFields(v.Fields).Log(level, args...)
+// WithError writes log with err as a field. Causes and stack trace of err
+// are shown as continuation lines.
+func WithError(err error) *MultiLoggerWithFields {
+	return mLogger.WithFields(logrus.Fields{errorKey: withStack(err)})
+}
+
+// fields returns a copy of fields of v, if v is overridden by
+// MultiLoggerWithFields
+func (v *MultiLogger) fields() logrus.Fields {
+	fields := logrus.Fields{}
+	if wf, ok := v.Self().(*MultiLoggerWithFields); ok {
+		for k, value := range wf.Fields {
+			fields[k] = value
+		}
 	}
+	return fields
+}
 
-	if v.FileLogger != nil {
-		v.FileLogger.WithFields(v.Fields).Log(level, args...)
+// WithFields returns a new logger with fields of v and fields. The new
+// fields override fields of v with the same keys, and v is not changed.
+func (v *MultiLogger) WithFields(fields map[string]interface{}) *MultiLoggerWithFields {
+	merged := v.fields()
+	for k, value := range fields {
+		merged[k] = value
 	}
+	return newLoggerWithFields(*v, merged)
 }
 
-// Logf defines core log methods for MultiLoggerWithFields
-func (v *MultiLoggerWithFields) Logf(level logrus.Level, format string, args ...interface{}) {
-	if v.StdLogger != nil {
-		v.StdLogger.WithFields(v.Fields).Logf(level, format, args...)
+// WithField returns a new logger with fields of v and one more field
+func (v *MultiLogger) WithField(key string, value interface{}) *MultiLoggerWithFields {
+	return v.WithFields(logrus.Fields{key: value})
+}
+
+// WithError returns a new logger with fields of v and err as a field
+func (v *MultiLogger) WithError(err error) *MultiLoggerWithFields {
+	return v.WithFields(logrus.Fields{errorKey: withStack(err)})
+}
+
+// Log defines core log methods for MultiLoggerWithFields
+func (v *MultiLoggerWithFields) Log(level logrus.Level, args ...interface{}) {
+	if mSampler != nil && !v.allow(level, fmt.Sprint(args...)) {
+		return
 	}
+	fields := v.resolveFields(level)
+	v.logEach(level, func(l *logrus.Logger) {
+		l.WithFields(fields).Log(level, args...)
+	})
+}
 
-	if v.FileLogger != nil {
-		v.FileLogger.WithFields(v.Fields).Logf(level, format, args...)
+// Logf defines core log methods for MultiLoggerWithFields
+func (v *MultiLoggerWithFields) Logf(level logrus.Level, format string, args ...interface{}) {
+	if mSampler != nil && !v.allow(level, format) {
+		return
 	}
+	fields := v.resolveFields(level)
+	v.logEach(level, func(l *logrus.Logger) {
+		l.WithFields(fields).Logf(level, format, args...)
+	})
 }
 
 // Logln defines core log methods for MultiLoggerWithFields
 func (v *MultiLoggerWithFields) Logln(level logrus.Level, args ...interface{}) {
-	if v.StdLogger != nil {
-		v.StdLogger.WithFields(v.Fields).Logln(level, args...)
+	if mSampler != nil && !v.allow(level, fmt.Sprint(args...)) {
+		return
+	}
+	fields := v.resolveFields(level)
+	v.logEach(level, func(l *logrus.Logger) {
+		l.WithFields(fields).Logln(level, args...)
+	})
+}
+
+// resolveFields evaluates lazy field values once for all loggers, if any
+// logger or sink will write entries of level. Lazy values must not be
+// passed to logrus, which drops fields of func values.
+func (v *MultiLoggerWithFields) resolveFields(level logrus.Level) logrus.Fields {
+	if !formatter.HasLazyValue(v.Fields) || !v.IsLevelEnabled(level) {
+		return v.Fields
 	}
 
-	if v.FileLogger != nil {
-		v.FileLogger.WithFields(v.Fields).Logln(level, args...)
+	fields := make(logrus.Fields, len(v.Fields))
+	for k, value := range v.Fields {
+		fields[k] = value
 	}
+	formatter.ResolveFields(fields)
+	return fields
+}
+
+// LazyValue is a field value evaluated only when the entry is written
+type LazyValue func() interface{}
+
+// Value evaluates the field value
+func (f LazyValue) Value() interface{} {
+	return f()
 }
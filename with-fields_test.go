@@ -2,6 +2,7 @@ package log
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -63,3 +64,128 @@ ERRO[<time>]: with-field # 1                               (period=2m0s size=10M
 	assert.Nil(err)
 	assert.Equal(expect, filterTime(string(data)))
 }
+
+func TestChainedWithFields(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		stderr: &buffer,
+	})
+	defer Init(Options{})
+
+	fields := map[string]interface{}{
+		"size": "10MB",
+	}
+	parent := WithFields(fields)
+	fields["size"] = "20MB"
+
+	child := parent.WithField("attempt", "1").WithFields(map[string]interface{}{
+		"size": "30MB",
+	})
+	child.WithError(errors.New("timeout")).Error("with-error")
+	child.Error("child")
+	parent.Error("parent")
+	WithError(errors.New("failure")).Warn("warn")
+
+	assert.Equal(map[string]interface{}{"size": "10MB"}, map[string]interface{}(parent.Fields))
+
+	expect = `ERROR: with-error                                   (attempt=1 error=timeout size=30MB)
+ERROR: child                                        (attempt=1 size=30MB)
+ERROR: parent                                       (size=10MB)
+WARNING: warn                                         (error=failure)
+`
+	assert.Equal(expect, buffer.String())
+}
+
+func TestLazyFields(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		count  int
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile: tmpLog,
+		stderr:  &buffer,
+	})
+	defer Init(Options{})
+
+	logger := WithFields(map[string]interface{}{
+		"dump": LazyValue(func() interface{} {
+			count++
+			return "large"
+		}),
+		"size": func() interface{} {
+			return "10MB"
+		},
+	})
+	logger.Debug("debug is filtered")
+	logger.Infof("info is filtered")
+	assert.Equal(0, count)
+
+	logger.Warn("warn #1")
+	assert.Equal(1, count)
+
+	expect = `WARNING: warn #1                                      (dump=large size=10MB)
+`
+	assert.Equal(expect, buffer.String())
+
+	expect = `WARN[<time>]: warn #1                                      (dump=large size=10MB)
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+}
+
+func TestLazyFieldsInSinks(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	tmpdir, err := ioutil.TempDir("", "multi-logger-")
+	assert.Nil(err)
+	defer func(dir string) {
+		os.RemoveAll(dir)
+	}(tmpdir)
+
+	tmpLog := filepath.Join(tmpdir, "log.txt")
+
+	Init(Options{
+		LogFile:            tmpLog,
+		LogLevel:           "warning",
+		FlightRecorderSize: 2,
+		stderr:             &buffer,
+	})
+	defer Init(Options{})
+
+	// Only the flight recorder records debug entries
+	WithFields(map[string]interface{}{
+		"dump": LazyValue(func() interface{} {
+			return "large"
+		}),
+		"plain": "p",
+	}).Debug("debug with lazy")
+	Error("failed")
+
+	expect = `DEBU[<time>]: debug with lazy                              (dump=large plain=p)
+ERRO[<time>]: failed
+`
+	data, err := ioutil.ReadFile(tmpLog)
+	assert.Nil(err)
+	assert.Equal(expect, filterTime(string(data)))
+}
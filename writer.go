@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Lines longer than this size are split into multiple entries
+const maxWriterLineSize = 64 * 1024
+
+// LineWriter is an io.WriteCloser which turns byte streams, such as the
+// output of subprocesses, into log entries, one entry for each line.
+// A carriage return without a newline (progress updates) discards the
+// line before it, so only the final state of the line is logged.
+type LineWriter struct {
+	mu        sync.Mutex
+	logger    *MultiLoggerWithFields
+	level     logrus.Level
+	line      bytes.Buffer
+	pendingCR bool
+}
+
+// Writer creates a LineWriter which logs lines at level with fields.
+// Call Close to log the trailing partial line.
+func Writer(level logrus.Level, fields map[string]interface{}) *LineWriter {
+	return &LineWriter{
+		logger: WithFields(fields),
+		level:  level,
+	}
+}
+
+// Write logs each complete line in p, and keeps the partial line
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, c := range p {
+		if w.pendingCR {
+			w.pendingCR = false
+			if c != '\n' {
+				// Progress update, overwrite current line
+				w.line.Reset()
+			}
+		}
+
+		switch c {
+		case '\n':
+			w.emit()
+		case '\r':
+			w.pendingCR = true
+		default:
+			w.line.WriteByte(c)
+			if w.line.Len() >= maxWriterLineSize {
+				w.emit()
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// Close logs the trailing partial line
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pendingCR = false
+	if w.line.Len() > 0 {
+		w.emit()
+	}
+	return nil
+}
+
+func (w *LineWriter) emit() {
+	w.logger.Log(w.level, w.line.String())
+	w.line.Reset()
+}
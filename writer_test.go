@@ -0,0 +1,41 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		buffer bytes.Buffer
+		expect string
+	)
+
+	Init(Options{
+		Verbose: 1,
+		stderr:  &buffer,
+	})
+	defer Init(Options{})
+
+	w := Writer(logrus.InfoLevel, map[string]interface{}{"cmd": "git"})
+	fmt.Fprint(w, "Cloning into 'repo'...\nReceiving objects:  10%\r")
+	fmt.Fprint(w, "Receiving objects:  50%\rReceiving objects: 100%, done.\r")
+	fmt.Fprint(w, "\nwindows line\r\nline 1\nline")
+	fmt.Fprint(w, " 2\n\npartial")
+	assert.Nil(w.Close())
+
+	expect = `INFO: Cloning into 'repo'...                       (cmd=git)
+INFO: Receiving objects: 100%, done.               (cmd=git)
+INFO: windows line                                 (cmd=git)
+INFO: line 1                                       (cmd=git)
+INFO: line 2                                       (cmd=git)
+INFO:                                              (cmd=git)
+INFO: partial                                      (cmd=git)
+`
+	assert.Equal(expect, buffer.String())
+}